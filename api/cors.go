@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// corsMiddleware wraps next so that pages on other origins may call it.
+// ALLOWED_ORIGINS is a comma-separated list of origins, or * for any,
+// which is the default. Preflight OPTIONS requests are answered with 204
+// without reaching next.
+func corsMiddleware(next http.Handler) http.Handler {
+	allowed := allowedOrigins()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header := w.Header()
+		origin := req.Header.Get("Origin")
+		switch {
+		case slices.Contains(allowed, "*"):
+			header.Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && slices.Contains(allowed, origin):
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+		}
+		header.Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		header.Set("Access-Control-Allow-Headers", "Content-Type")
+
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// allowedOrigins returns the origins listed in ALLOWED_ORIGINS, or * when
+// it is not set.
+func allowedOrigins() []string {
+	env := os.Getenv("ALLOWED_ORIGINS")
+	if strings.TrimSpace(env) == "" {
+		return []string{"*"}
+	}
+
+	var allowed []string
+	for _, origin := range strings.Split(env, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowed = append(allowed, origin)
+		}
+	}
+	return allowed
+}
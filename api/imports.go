@@ -0,0 +1,16 @@
+package main
+
+import (
+	"bananaScript/evaluator"
+	"os"
+)
+
+// importResolver returns how code sent to the API resolves imports. The
+// server's files are off limits unless IMPORT_DIR names a directory, in
+// which case imports are confined to it.
+func importResolver() evaluator.ImportResolver {
+	if dir := os.Getenv("IMPORT_DIR"); dir != "" {
+		return evaluator.FileResolver{Root: dir}
+	}
+	return evaluator.DisabledResolver{}
+}
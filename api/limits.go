@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultExecutionTimeout = 5 * time.Second
+	defaultMaxCodeBytes     = 64 << 10
+
+	defaultSessionIdleTimeout = 30 * time.Minute
+)
+
+// maxSleep is the longest a single sleep may pause a request, so that
+// code cannot hold a connection open just by idling.
+const maxSleep = time.Second
+
+// executionTimeout returns how long a single /api/execute request may run,
+// taken in milliseconds from EXECUTION_TIMEOUT_MS when that is set to a
+// positive number.
+func executionTimeout() time.Duration {
+	ms, err := strconv.Atoi(os.Getenv("EXECUTION_TIMEOUT_MS"))
+	if err != nil || ms <= 0 {
+		return defaultExecutionTimeout
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// maxCodeBytes returns how large the body of a request carrying code may
+// be, taken from MAX_CODE_BYTES when that is set to a positive number.
+func maxCodeBytes() int64 {
+	n, err := strconv.ParseInt(os.Getenv("MAX_CODE_BYTES"), 10, 64)
+	if err != nil || n <= 0 {
+		return defaultMaxCodeBytes
+	}
+	return n
+}
+
+// sessionIdleTimeout returns how long a session may go unused before it
+// expires, taken in milliseconds from SESSION_IDLE_TIMEOUT_MS when that is
+// set to a positive number.
+func sessionIdleTimeout() time.Duration {
+	ms, err := strconv.Atoi(os.Getenv("SESSION_IDLE_TIMEOUT_MS"))
+	if err != nil || ms <= 0 {
+		return defaultSessionIdleTimeout
+	}
+	return time.Duration(ms) * time.Millisecond
+}
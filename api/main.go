@@ -6,22 +6,34 @@ import (
 	"bananaScript/object"
 	"bananaScript/parser"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	_ "github.com/joho/godotenv/autoload"
 )
 
 type Request struct {
-	Code string `json:"code"`
+	Code      string `json:"code"`
+	SessionID string `json:"session_id,omitempty"`
 }
 
 type Response struct {
-	Output string   `json:"output"`
-	Errors []string `json:"errors"`
+	Output string        `json:"output"`
+	Errors []ErrorDetail `json:"errors"`
+}
+
+// ErrorDetail is one error in a Response. Syntax errors carry the line and
+// column they were found at, counting from 1; other errors leave them out.
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Col     int    `json:"col,omitempty"`
 }
 
 type HealthResponse struct {
@@ -51,60 +63,122 @@ func healthCheck(w http.ResponseWriter, req *http.Request) {
 	w.Write(jsonData)
 }
 
-func executeCode(w http.ResponseWriter, req *http.Request) {
+// decodeRequest reads the Request in req's body, answering with 413 when
+// the body is larger than maxCodeBytes allows and 400 when it is not a
+// Request. It reports whether there is a request to handle.
+func decodeRequest(w http.ResponseWriter, req *http.Request) (Request, bool) {
 	var body Request
-	var logs bytes.Buffer
 
-	log.SetOutput(&logs)
+	limit := maxCodeBytes()
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, limit)).Decode(&body)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		msg := fmt.Sprintf("request body is larger than the limit of %d bytes", limit)
+		http.Error(w, string(stringToJson("", []ErrorDetail{{Message: msg}}, true)), http.StatusRequestEntityTooLarge)
+		return body, false
+	case err != nil:
+		http.Error(w, string(stringToJson("", []ErrorDetail{{Message: err.Error()}}, true)), http.StatusBadRequest)
+		return body, false
+	}
+	return body, true
+}
 
-	err := json.NewDecoder(req.Body).Decode(&body)
-	if err != nil {
-		http.Error(w, string(stringToJson("", []string{err.Error()}, true)), http.StatusBadRequest)
-		return
+// newEnvironment returns an environment set up to run code sent to the
+// API.
+func newEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	evaluator.SetImportResolver(env, importResolver(), "")
+	evaluator.SetMaxSleep(env, maxSleep)
+	return env
+}
+
+// executeCode runs the code in each request in a fresh environment, or in
+// the environment of the session named by its session_id, answering with
+// 404 when that session does not exist or has expired.
+func executeCode(sessions *sessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		body, ok := decodeRequest(w, req)
+		if !ok {
+			return
+		}
+
+		var response Response
+		var status int
+		if body.SessionID == "" {
+			response, status = runCode(req.Context(), newEnvironment(), body.Code)
+		} else {
+			s := sessions.get(body.SessionID)
+			if s == nil {
+				http.Error(w, string(stringToJson("", []ErrorDetail{{Message: sessionNotFoundMessage(body.SessionID)}}, true)), http.StatusNotFound)
+				return
+			}
+			s.mu.Lock()
+			response, status = runCode(req.Context(), s.env, body.Code)
+			s.touch(time.Now())
+			s.mu.Unlock()
+		}
+
+		jsonData, err := json.Marshal(response)
+		if err != nil {
+			log.Println("Error marshaling JSON:", err)
+			http.Error(w, "Error creating response", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write(jsonData)
 	}
+}
 
-	codeInput := body.Code
+// runCode parses and evaluates code in env, stopping it once the
+// execution timeout passes, and returns the response to send along with
+// its HTTP status.
+func runCode(ctx context.Context, env *object.Environment, code string) (Response, int) {
+	var printed bytes.Buffer
+	evaluator.SetOutput(env, &printed)
 
-	l := lexer.New(codeInput)
+	l := lexer.New(code)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	if len(p.Errors()) > 0 {
-		jsonData := stringToJson("", p.Errors(), true)
-
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(jsonData)
-		return
+		return Response{Errors: parseErrorDetails(p.ErrorDetails())}, http.StatusBadRequest
 	}
 
-	env := object.NewEnvironment()
-	output := evaluator.Eval(program, env)
+	timeout := executionTimeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	if output == nil {
-		fmt.Println("Output: nil")
-		fmt.Println("Errors:", p.Errors())
-		jsonData := stringToJson(""+"\n\nLogs:\n"+logs.String(), nil, false)
-		w.WriteHeader(http.StatusOK)
-		w.Write(jsonData)
-		return
-	}
+	output := evaluator.EvalWithContext(ctx, program, env)
 
-	errObj, ok := output.(*object.Error)
+	// Code that ran past the deadline has been stopped, whatever it
+	// returned on the way out.
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		msg := fmt.Sprintf("execution timed out after %dms", timeout.Milliseconds())
+		return Response{Errors: []ErrorDetail{{Message: msg}}}, http.StatusRequestTimeout
+	}
 
-	fmt.Println("Output:", output.Inspect())
-	fmt.Println("Errors:", p.Errors())
+	if output == nil {
+		return Response{Output: "\n\nLogs:\n" + printed.String()}, http.StatusOK
+	}
 
-	if ok {
-		http.Error(w, string(stringToJson("", []string{errObj.Message}, true)), http.StatusBadRequest)
-		return
+	if errObj, ok := output.(*object.Error); ok {
+		return Response{Errors: []ErrorDetail{{Message: errObj.Message}}}, http.StatusBadRequest
 	}
 
-	jsonData := stringToJson(output.Inspect()+"\n\nLogs:\n"+logs.String(), nil, false)
-	w.WriteHeader(http.StatusOK)
-	w.Write(jsonData)
+	return Response{Output: output.Inspect() + "\n\nLogs:\n" + printed.String()}, http.StatusOK
+}
 
+func parseErrorDetails(errors []parser.ParseError) []ErrorDetail {
+	details := make([]ErrorDetail, len(errors))
+	for i, err := range errors {
+		details[i] = ErrorDetail{Message: err.Message, Line: err.Line, Col: err.Column}
+	}
+	return details
 }
 
-func stringToJson(message string, errors []string, isError bool) []byte {
+func stringToJson(message string, errors []ErrorDetail, isError bool) []byte {
 	var response Response
 	if isError {
 		response = Response{Errors: errors}
@@ -121,13 +195,23 @@ func stringToJson(message string, errors []string, isError bool) []byte {
 	return jsonData
 }
 
-func main() {
-	http.HandleFunc("/", indexHtml)
-	http.HandleFunc("/health", healthCheck)
-	http.HandleFunc("/api/execute", executeCode)
+func newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", indexHtml)
+	mux.HandleFunc("/health", healthCheck)
+	limiter := rateLimiterFromEnv()
+	sessions := newSessionStore(sessionIdleTimeout())
+	mux.Handle("/api/execute", limiter.limit(executeCode(sessions)))
+	mux.Handle("POST /api/session", limiter.limit(createSession(sessions)))
+	mux.Handle("DELETE /api/session/{id}", limiter.limit(deleteSession(sessions)))
+	mux.Handle("POST /api/parse", limiter.limit(http.HandlerFunc(parseCode)))
+	mux.Handle("/ws/repl", limiter.limit(replSession(limiter, replIdleTimeout)))
+	return mux
+}
 
+func main() {
 	port := os.Getenv("PORT")
 
 	fmt.Printf("Listening on port %s...\n", port)
-	http.ListenAndServe(":"+port, nil)
+	http.ListenAndServe(":"+port, corsMiddleware(newRouter()))
 }
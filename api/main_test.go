@@ -0,0 +1,715 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func postCode(t *testing.T, url string, code string) (*http.Response, Response) {
+	t.Helper()
+	return postRequest(t, url, Request{Code: code})
+}
+
+func postRequest(t *testing.T, url string, request Request) (*http.Response, Response) {
+	t.Helper()
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("could not encode request: %v", err)
+	}
+
+	res, err := http.Post(url+"/api/execute", "application/json", strings.NewReader(string(payload)))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	var body Response
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	return res, body
+}
+
+func TestExecuteUnterminatedString(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postCode(t, server.URL, `let s = "never closed`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	if len(body.Errors) == 0 {
+		t.Fatalf("expected errors in response, got none")
+	}
+	expected := "illegal token: unterminated string literal at position 9"
+	if body.Errors[0].Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, body.Errors[0].Message)
+	}
+
+	res, body = postCode(t, server.URL, `let s = "closed"; s`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("server did not recover. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	if !strings.HasPrefix(body.Output, "closed") {
+		t.Errorf("wrong output. got=%q", body.Output)
+	}
+}
+
+func TestExecuteSyntaxErrorPosition(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postCode(t, server.URL, "let a = 1;\nlet b = 2;\nlet = 3;")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	expected := ErrorDetail{Message: "expected next token to be IDENT, got = instead", Line: 3, Col: 5}
+	if len(body.Errors) == 0 || body.Errors[0] != expected {
+		t.Errorf("wrong errors. expected=%+v, got=%+v", expected, body.Errors)
+	}
+
+	res, body = postCode(t, server.URL, "1 / 0")
+	if res.StatusCode != http.StatusBadRequest || len(body.Errors) != 1 {
+		t.Fatalf("wrong response. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	if body.Errors[0].Line != 0 || body.Errors[0].Col != 0 {
+		t.Errorf("runtime error has a position. got=%+v", body.Errors[0])
+	}
+}
+
+func TestExecuteDivisionByZero(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postCode(t, server.URL, `1/0`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	if len(body.Errors) == 0 || body.Errors[0].Message != "division by zero" {
+		t.Errorf("wrong errors. expected=%q, got=%v", "division by zero", body.Errors)
+	}
+
+	res, body = postCode(t, server.URL, `6 / 3`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("server did not recover. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	if !strings.HasPrefix(body.Output, "2") {
+		t.Errorf("wrong output. got=%q", body.Output)
+	}
+}
+
+func TestExecuteAssertionFailure(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postCode(t, server.URL, `assert(1 > 2, "1 is not greater than 2")`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	expected := "1 is not greater than 2"
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+}
+
+func TestExecuteInputIsUnavailable(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postCode(t, server.URL, `input("name? ")`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	expected := "input() is not available in API mode"
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+}
+
+func TestExecuteSleepIsLimited(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	start := time.Now()
+	res, body := postCode(t, server.URL, `sleep(60000)`)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request ran for %s", elapsed)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	expected := "sleep is limited to 1000ms, got 60000"
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+
+	res, body = postCode(t, server.URL, `sleep(1); "awake"`)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(body.Output, "awake") {
+		t.Errorf("short sleep failed. got status=%d output=%q errors=%v", res.StatusCode, body.Output, body.Errors)
+	}
+}
+
+func TestExecuteImport(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	t.Setenv("IMPORT_DIR", "")
+	res, body := postCode(t, server.URL, `import "../go.mod"`)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusBadRequest, res.StatusCode)
+	}
+	expected := `cannot import "../go.mod": import is disabled`
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "greet.bs"), []byte(`let greet = fn(name) { "hi " + name }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("IMPORT_DIR", dir)
+
+	res, body = postCode(t, server.URL, `import "greet"; greet("ada")`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("import from IMPORT_DIR failed. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	if !strings.HasPrefix(body.Output, "hi ada") {
+		t.Errorf("wrong output. got=%q", body.Output)
+	}
+
+	_, body = postCode(t, server.URL, `import "../greet"`)
+	expected = `cannot import "../greet": ../greet is outside the import directory`
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	t.Setenv("EXECUTION_TIMEOUT_MS", "50")
+	start := time.Now()
+	res, body := postCode(t, server.URL, `let f = fn(n) { if (n > 0) { f(n - 1) } else { 0 } }; while (true) { f(10) }`)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("request ran for %s with a 50ms timeout", elapsed)
+	}
+	if res.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusRequestTimeout, res.StatusCode)
+	}
+	expected := "execution timed out after 50ms"
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+
+	res, body = postCode(t, server.URL, `let f = fn(n) { if (n > 0) { f(n - 1) } else { 0 } }; try { while (true) { f(10) } } catch (e) { 1 }`)
+	if res.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("try caught the timeout. got status=%d output=%q errors=%v", res.StatusCode, body.Output, body.Errors)
+	}
+
+	res, body = postCode(t, server.URL, `while (true) {}`)
+	if res.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("empty loop was not stopped. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+
+	res, body = postCode(t, server.URL, `sleep(1000)`)
+	if res.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("sleep was not stopped. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+
+	t.Setenv("EXECUTION_TIMEOUT_MS", "")
+	if got := executionTimeout(); got != 5*time.Second {
+		t.Errorf("wrong default timeout. got=%s", got)
+	}
+}
+
+func TestExecuteCapturesPrintedOutput(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postCode(t, server.URL, `println("hello"); print("a", 1); puts("", 2); 42`)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("wrong status code. got=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	expected := "42\n\nLogs:\nhello\na 1\n2\n"
+	if body.Output != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, body.Output)
+	}
+
+	_, body = postCode(t, server.URL, `1`)
+	if body.Output != "1\n\nLogs:\n" {
+		t.Errorf("output of a previous request leaked. got=%q", body.Output)
+	}
+}
+
+func TestExecuteRateLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RPS", "0.01")
+	t.Setenv("RATE_LIMIT_BURST", "2")
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		res, body := postCode(t, server.URL, `1 + 1`)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("request %d within the burst failed. got status=%d errors=%v", i, res.StatusCode, body.Errors)
+		}
+	}
+
+	res, body := postCode(t, server.URL, `1 + 1`)
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusTooManyRequests, res.StatusCode)
+	}
+	expected := "rate limit exceeded, try again later"
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+
+	health, err := http.Get(server.URL + "/health")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	health.Body.Close()
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("health check was rate limited. got status=%d", health.StatusCode)
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	rl := newRateLimiter(1000, 1)
+	if !rl.allow("a") {
+		t.Fatalf("first request was refused")
+	}
+	if rl.allow("a") {
+		t.Errorf("request past the burst was allowed")
+	}
+	if !rl.allow("b") {
+		t.Errorf("another client shares the first one's bucket")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if !rl.allow("a") {
+		t.Errorf("bucket did not refill")
+	}
+}
+
+func TestCorsHeaders(t *testing.T) {
+	server := httptest.NewServer(corsMiddleware(newRouter()))
+	defer server.Close()
+
+	res, _ := postCode(t, server.URL, `1 + 1`)
+	if got := res.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("wrong allowed origin. expected=%q, got=%q", "*", got)
+	}
+	if got := res.Header.Get("Access-Control-Allow-Methods"); got != "GET, POST, DELETE, OPTIONS" {
+		t.Errorf("wrong allowed methods. got=%q", got)
+	}
+	if got := res.Header.Get("Access-Control-Allow-Headers"); got != "Content-Type" {
+		t.Errorf("wrong allowed headers. got=%q", got)
+	}
+
+	req, err := http.NewRequest(http.MethodOptions, server.URL+"/api/execute", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", "https://playground.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	preflight, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	preflight.Body.Close()
+	if preflight.StatusCode != http.StatusNoContent {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusNoContent, preflight.StatusCode)
+	}
+	if got := preflight.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("wrong allowed origin on preflight. got=%q", got)
+	}
+	if got := preflight.Header.Get("Access-Control-Allow-Methods"); got != "GET, POST, DELETE, OPTIONS" {
+		t.Errorf("wrong allowed methods on preflight. got=%q", got)
+	}
+}
+
+func TestCorsAllowedOrigins(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "https://a.example, https://b.example")
+	server := httptest.NewServer(corsMiddleware(newRouter()))
+	defer server.Close()
+
+	origins := map[string]string{
+		"https://b.example":    "https://b.example",
+		"https://evil.example": "",
+	}
+	for origin, expected := range origins {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/health", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", origin)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		res.Body.Close()
+		if got := res.Header.Get("Access-Control-Allow-Origin"); got != expected {
+			t.Errorf("wrong allowed origin for %s. expected=%q, got=%q", origin, expected, got)
+		}
+	}
+}
+
+func dialRepl(t *testing.T, serverURL string) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(serverURL, "http")+"/ws/repl", nil)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	return conn
+}
+
+func sendRepl(t *testing.T, conn *websocket.Conn, code string) Response {
+	t.Helper()
+
+	if err := conn.WriteJSON(Request{Code: code}); err != nil {
+		t.Fatalf("could not send code: %v", err)
+	}
+	var body Response
+	if err := conn.ReadJSON(&body); err != nil {
+		t.Fatalf("could not read response: %v", err)
+	}
+	return body
+}
+
+func TestReplSessionKeepsBindings(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	conn := dialRepl(t, server.URL)
+	defer conn.Close()
+
+	if body := sendRepl(t, conn, `let x = 40; let add = fn(a, b) { a + b };`); len(body.Errors) != 0 {
+		t.Fatalf("first message failed. errors=%v", body.Errors)
+	}
+	body := sendRepl(t, conn, `println("adding"); add(x, 2)`)
+	if body.Output != "42\n\nLogs:\nadding\n" {
+		t.Errorf("wrong output. got=%q", body.Output)
+	}
+
+	body = sendRepl(t, conn, "let y = 1;\nlet = 2;")
+	expected := ErrorDetail{Message: "expected next token to be IDENT, got = instead", Line: 2, Col: 5}
+	if len(body.Errors) == 0 || body.Errors[0] != expected {
+		t.Errorf("wrong errors. expected=%+v, got=%+v", expected, body.Errors)
+	}
+	if body := sendRepl(t, conn, `x / 0`); len(body.Errors) == 0 || body.Errors[0].Message != "division by zero" {
+		t.Errorf("wrong errors. got=%v", body.Errors)
+	}
+	if body := sendRepl(t, conn, `x`); !strings.HasPrefix(body.Output, "40") {
+		t.Errorf("errors lost the session's bindings. got output=%q errors=%v", body.Output, body.Errors)
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	var malformed Response
+	if err := conn.ReadJSON(&malformed); err != nil {
+		t.Fatalf("could not read response: %v", err)
+	}
+	if len(malformed.Errors) == 0 || malformed.Errors[0].Message != "message must be a JSON object with a code field" {
+		t.Errorf("wrong errors for a malformed message. got=%v", malformed.Errors)
+	}
+
+	other := dialRepl(t, server.URL)
+	defer other.Close()
+	body = sendRepl(t, other, `x`)
+	if len(body.Errors) == 0 || body.Errors[0].Message != "identifier not found: x" {
+		t.Errorf("sessions share bindings. got output=%q errors=%v", body.Output, body.Errors)
+	}
+}
+
+func TestReplSessionIdleTimeout(t *testing.T) {
+	server := httptest.NewServer(replSession(newRateLimiter(100, 100), 50*time.Millisecond))
+	defer server.Close()
+
+	conn := dialRepl(t, server.URL)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if err == nil {
+		t.Fatalf("idle connection got a message")
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		t.Errorf("idle connection was still open after 2s")
+	}
+}
+
+func postParse(t *testing.T, url string, code string) (*http.Response, ParseResponse) {
+	t.Helper()
+
+	payload, err := json.Marshal(Request{Code: code})
+	if err != nil {
+		t.Fatalf("could not encode request: %v", err)
+	}
+
+	res, err := http.Post(url+"/api/parse", "application/json", strings.NewReader(string(payload)))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	var body ParseResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	return res, body
+}
+
+func TestParseValidCode(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postParse(t, server.URL, `let x = 1 / 0;`)
+	if res.StatusCode != http.StatusOK || !body.Valid || len(body.Errors) != 0 {
+		t.Fatalf("valid code was rejected. got status=%d body=%+v", res.StatusCode, body)
+	}
+
+	var tree struct {
+		Statements []struct {
+			Type string `json:"type"`
+			Name struct {
+				Value string `json:"value"`
+			} `json:"name"`
+			Value struct {
+				Type     string `json:"type"`
+				Operator string `json:"operator"`
+			} `json:"value"`
+		} `json:"statements"`
+	}
+	if err := json.Unmarshal(body.AST, &tree); err != nil {
+		t.Fatalf("ast is not a JSON object: %v (%s)", err, body.AST)
+	}
+	if len(tree.Statements) != 1 {
+		t.Fatalf("wrong number of statements. got=%s", body.AST)
+	}
+	let := tree.Statements[0]
+	if let.Type != "LetStatement" || let.Name.Value != "x" || let.Value.Type != "InfixExpression" || let.Value.Operator != "/" {
+		t.Errorf("wrong ast. got=%s", body.AST)
+	}
+}
+
+func TestParseInvalidCode(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, body := postParse(t, server.URL, "let a = 1;\nlet = 2;")
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusOK, res.StatusCode)
+	}
+	if body.Valid || body.AST != nil {
+		t.Errorf("invalid code was accepted. got=%+v", body)
+	}
+	expected := ErrorDetail{Message: "expected next token to be IDENT, got = instead", Line: 2, Col: 5}
+	if len(body.Errors) == 0 || body.Errors[0] != expected {
+		t.Errorf("wrong errors. expected=%+v, got=%+v", expected, body.Errors)
+	}
+
+	res, err := http.Post(server.URL+"/api/parse", "application/json", strings.NewReader("not json"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("malformed request was not rejected. got status=%d", res.StatusCode)
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	t.Setenv("RATE_LIMIT_RPS", "0.01")
+	t.Setenv("RATE_LIMIT_BURST", "1")
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/api/parse")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status code for GET. expected=%d, got=%d", http.StatusMethodNotAllowed, res.StatusCode)
+	}
+
+	if res, _ := postParse(t, server.URL, `1`); res.StatusCode != http.StatusOK {
+		t.Fatalf("request within the burst failed. got status=%d", res.StatusCode)
+	}
+	res, err = http.Post(server.URL+"/api/parse", "application/json", strings.NewReader(`{"code": "1"}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("parse was not rate limited. expected=%d, got=%d", http.StatusTooManyRequests, res.StatusCode)
+	}
+}
+
+func TestExecuteBodySizeLimit(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	code := "let x = 1;" + strings.Repeat(" ", 64<<10)
+	res, body := postCode(t, server.URL, code)
+	if res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("wrong status code. expected=%d, got=%d", http.StatusRequestEntityTooLarge, res.StatusCode)
+	}
+	expected := "request body is larger than the limit of 65536 bytes"
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+
+	t.Setenv("MAX_CODE_BYTES", "256")
+	if res, body := postCode(t, server.URL, strings.Repeat("1;", 100)); res.StatusCode != http.StatusOK {
+		t.Errorf("body under the limit was rejected. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	if res, _ := postCode(t, server.URL, strings.Repeat("1;", 200)); res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("MAX_CODE_BYTES was ignored. got status=%d", res.StatusCode)
+	}
+	if res, _ := postParse(t, server.URL, strings.Repeat("1;", 200)); res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("parse endpoint ignored MAX_CODE_BYTES. got status=%d", res.StatusCode)
+	}
+}
+
+func createTestSession(t *testing.T, url string) string {
+	t.Helper()
+
+	res, err := http.Post(url+"/api/session", "application/json", nil)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("wrong status code. expected=%d, got=%d", http.StatusCreated, res.StatusCode)
+	}
+
+	var body SessionResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	if body.SessionID == "" {
+		t.Fatalf("response has no session_id")
+	}
+	return body.SessionID
+}
+
+func deleteTestSession(t *testing.T, url string, id string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodDelete, url+"/api/session/"+id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func TestExecuteSessionKeepsBindings(t *testing.T) {
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	id := createTestSession(t, server.URL)
+	if other := createTestSession(t, server.URL); other == id {
+		t.Fatalf("two sessions got the same id %q", id)
+	}
+
+	res, body := postRequest(t, server.URL, Request{Code: `let add = fn(a, b) { a + b };`, SessionID: id})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("first request failed. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	_, body = postRequest(t, server.URL, Request{Code: `add(40, 2)`, SessionID: id})
+	if !strings.HasPrefix(body.Output, "42") {
+		t.Errorf("session lost its bindings. got output=%q errors=%v", body.Output, body.Errors)
+	}
+
+	_, body = postCode(t, server.URL, `add(40, 2)`)
+	if len(body.Errors) == 0 || body.Errors[0].Message != "identifier not found: add" {
+		t.Errorf("request without a session saw its bindings. got output=%q errors=%v", body.Output, body.Errors)
+	}
+
+	if res := deleteTestSession(t, server.URL, id); res.StatusCode != http.StatusNoContent {
+		t.Errorf("wrong status code for delete. expected=%d, got=%d", http.StatusNoContent, res.StatusCode)
+	}
+	res, body = postRequest(t, server.URL, Request{Code: `add(40, 2)`, SessionID: id})
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("wrong status code for a deleted session. expected=%d, got=%d", http.StatusNotFound, res.StatusCode)
+	}
+	expected := `no session with id "` + id + `"`
+	if len(body.Errors) == 0 || body.Errors[0].Message != expected {
+		t.Errorf("wrong errors. expected=%q, got=%v", expected, body.Errors)
+	}
+	if res := deleteTestSession(t, server.URL, id); res.StatusCode != http.StatusNotFound {
+		t.Errorf("wrong status code for deleting twice. expected=%d, got=%d", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestExecuteSessionImport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "greet.bs"), []byte(`let greet = fn(name) { println("greeting"); "hi " + name }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("IMPORT_DIR", dir)
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	id := createTestSession(t, server.URL)
+	if res, body := postRequest(t, server.URL, Request{Code: `let m = import("greet"); m["greet"]("ada")`, SessionID: id}); res.StatusCode != http.StatusOK {
+		t.Fatalf("first request failed. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+
+	res, body := postRequest(t, server.URL, Request{Code: `m["greet"]("linus")`, SessionID: id})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("imported function failed in a later request. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+	if body.Output != "hi linus\n\nLogs:\ngreeting\n" {
+		t.Errorf("wrong output. got=%q", body.Output)
+	}
+}
+
+func TestExecuteSessionExpires(t *testing.T) {
+	t.Setenv("SESSION_IDLE_TIMEOUT_MS", "50")
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	id := createTestSession(t, server.URL)
+	if res, body := postRequest(t, server.URL, Request{Code: `let x = 1;`, SessionID: id}); res.StatusCode != http.StatusOK {
+		t.Fatalf("request to a new session failed. got status=%d errors=%v", res.StatusCode, body.Errors)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	res, _ := postRequest(t, server.URL, Request{Code: `x`, SessionID: id})
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("idle session did not expire. got status=%d", res.StatusCode)
+	}
+}
+
+func TestSessionStoreSweepsExpiredSessions(t *testing.T) {
+	sessions := newSessionStore(20 * time.Millisecond)
+	old := sessions.create()
+	time.Sleep(40 * time.Millisecond)
+	sessions.create()
+
+	if _, ok := sessions.sessions.Load(old); ok {
+		t.Errorf("expired session %q was not swept", old)
+	}
+}
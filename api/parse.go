@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bananaScript/lexer"
+	"bananaScript/parser"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// ParseResponse is the result of checking code's syntax: the program's
+// syntax tree when it parses, and its syntax errors otherwise.
+type ParseResponse struct {
+	Valid  bool            `json:"valid"`
+	AST    json.RawMessage `json:"ast,omitempty"`
+	Errors []ErrorDetail   `json:"errors,omitempty"`
+}
+
+// parseCode checks the syntax of the code in the request without running
+// it. Code that does not parse is still answered with 200, since checking
+// it succeeded; only a malformed or oversized request is an error.
+func parseCode(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	body, ok := decodeRequest(w, req)
+	if !ok {
+		return
+	}
+
+	p := parser.New(lexer.New(body.Code))
+	program := p.ParseProgram()
+
+	var err error
+	response := ParseResponse{Valid: len(p.Errors()) == 0}
+	if response.Valid {
+		if response.AST, err = json.Marshal(program); err != nil {
+			log.Println("Error marshaling AST:", err)
+			http.Error(w, "Error creating response", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		response.Errors = parseErrorDetails(p.ErrorDetails())
+	}
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		log.Println("Error marshaling JSON:", err)
+		http.Error(w, "Error creating response", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonData)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRateLimitRPS   = 5
+	defaultRateLimitBurst = 10
+
+	rateLimitMessage = "rate limit exceeded, try again later"
+)
+
+// rateLimiter hands each client IP a token bucket that holds up to burst
+// tokens and refills at rps tokens a second. A request takes a token, and
+// is turned away when the bucket is empty.
+type rateLimiter struct {
+	rps   float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rps float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rps:       rps,
+		burst:     float64(burst),
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// rateLimiterFromEnv returns a limiter configured by RATE_LIMIT_RPS and
+// RATE_LIMIT_BURST, using the defaults for either when it is not set to a
+// positive number.
+func rateLimiterFromEnv() *rateLimiter {
+	rps, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_RPS"), 64)
+	if err != nil || rps <= 0 {
+		rps = defaultRateLimitRPS
+	}
+	burst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST"))
+	if err != nil || burst <= 0 {
+		burst = defaultRateLimitBurst
+	}
+	return newRateLimiter(rps, burst)
+}
+
+// allow reports whether the client key may make a request now, taking a
+// token from its bucket if so.
+func (rl *rateLimiter) allow(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: rl.burst, last: now}
+		rl.buckets[key] = b
+	}
+	b.tokens = rl.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (rl *rateLimiter) refill(b *bucket, now time.Time) float64 {
+	return min(rl.burst, b.tokens+now.Sub(b.last).Seconds()*rl.rps)
+}
+
+// sweep drops, at most once a minute, the buckets that have refilled
+// completely, since a full bucket is the same as none. This keeps clients
+// that have gone away from holding memory.
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+	rl.lastSweep = now
+	for key, b := range rl.buckets {
+		if rl.refill(b, now) >= rl.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+// limit wraps next so that clients, told apart by their IP address, are
+// answered with 429 Too Many Requests once they run out of tokens.
+func (rl *rateLimiter) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !rl.allow(clientIP(req)) {
+			http.Error(w, string(stringToJson("", []ErrorDetail{{Message: rateLimitMessage}}, true)), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// clientIP returns the IP address a request came from. Forwarding headers
+// are ignored, since any client can set them.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
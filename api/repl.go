@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// replIdleTimeout is how long a REPL connection may go without a message
+// before it is closed.
+const replIdleTimeout = 5 * time.Minute
+
+var replUpgrader = websocket.Upgrader{CheckOrigin: replOriginAllowed}
+
+// replOriginAllowed accepts connections from the origins in
+// ALLOWED_ORIGINS, from the page the server itself serves, and from
+// clients that are not browsers and send no Origin.
+func replOriginAllowed(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	allowed := allowedOrigins()
+	if slices.Contains(allowed, "*") || slices.Contains(allowed, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == req.Host
+}
+
+// replSession serves a WebSocket connection as a REPL. Each message is a
+// Request and is answered with a Response, like /api/execute, but every
+// message is evaluated in the same environment, so bindings carry over
+// from one to the next. Each message counts against the client's rate
+// limit, and the connection is closed once it has been idle for
+// idleTimeout.
+func replSession(limiter *rateLimiter, idleTimeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		conn, err := replUpgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadLimit(maxCodeBytes())
+
+		env := newEnvironment()
+
+		for {
+			conn.SetReadDeadline(time.Now().Add(idleTimeout))
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			var response Response
+			var body Request
+			switch {
+			case !limiter.allow(clientIP(req)):
+				response = Response{Errors: []ErrorDetail{{Message: rateLimitMessage}}}
+			case json.Unmarshal(message, &body) != nil:
+				response = Response{Errors: []ErrorDetail{{Message: "message must be a JSON object with a code field"}}}
+			default:
+				response, _ = runCode(req.Context(), env, body.Code)
+			}
+
+			if err := conn.WriteJSON(response); err != nil {
+				log.Println("Error writing REPL response:", err)
+				return
+			}
+		}
+	}
+}
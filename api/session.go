@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bananaScript/object"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type SessionResponse struct {
+	SessionID string `json:"session_id"`
+}
+
+// session is an environment kept between /api/execute requests that name
+// it, so that code sent in one request can use what an earlier one
+// defined.
+type session struct {
+	// mu is held while code runs, so that requests to one session take
+	// turns instead of racing on its environment.
+	mu  sync.Mutex
+	env *object.Environment
+
+	lastUsed atomic.Int64
+}
+
+func (s *session) touch(now time.Time) {
+	s.lastUsed.Store(now.UnixNano())
+}
+
+func (s *session) expired(now time.Time, idleTimeout time.Duration) bool {
+	return now.Sub(time.Unix(0, s.lastUsed.Load())) > idleTimeout
+}
+
+// sessionStore holds the sessions by ID. A session that has not been used
+// for idleTimeout is dropped, either when it is next asked for or by a
+// sweep over the store that runs at most once per idleTimeout.
+type sessionStore struct {
+	idleTimeout time.Duration
+	sessions    sync.Map
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func newSessionStore(idleTimeout time.Duration) *sessionStore {
+	return &sessionStore{idleTimeout: idleTimeout, lastSweep: time.Now()}
+}
+
+// create starts a session with a fresh environment and returns its ID.
+func (ss *sessionStore) create() string {
+	now := time.Now()
+	ss.sweep(now)
+
+	s := &session{env: newEnvironment()}
+	s.touch(now)
+	id := newSessionID()
+	ss.sessions.Store(id, s)
+	return id
+}
+
+// get returns the session with the given ID, marking it as used, or nil
+// when there is none or it has expired.
+func (ss *sessionStore) get(id string) *session {
+	now := time.Now()
+	ss.sweep(now)
+
+	value, ok := ss.sessions.Load(id)
+	if !ok {
+		return nil
+	}
+	s := value.(*session)
+	if s.expired(now, ss.idleTimeout) {
+		ss.sessions.Delete(id)
+		return nil
+	}
+	s.touch(now)
+	return s
+}
+
+// delete drops the session with the given ID, reporting whether there was
+// one.
+func (ss *sessionStore) delete(id string) bool {
+	value, ok := ss.sessions.LoadAndDelete(id)
+	return ok && !value.(*session).expired(time.Now(), ss.idleTimeout)
+}
+
+func (ss *sessionStore) sweep(now time.Time) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if now.Sub(ss.lastSweep) < ss.idleTimeout {
+		return
+	}
+	ss.lastSweep = now
+	ss.sessions.Range(func(id, value any) bool {
+		if value.(*session).expired(now, ss.idleTimeout) {
+			ss.sessions.Delete(id)
+		}
+		return true
+	})
+}
+
+// newSessionID returns a random version 4 UUID.
+func newSessionID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func sessionNotFoundMessage(id string) string {
+	return fmt.Sprintf("no session with id %q", id)
+}
+
+// createSession answers POST /api/session with the ID of a new session.
+func createSession(sessions *sessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		jsonData, err := json.Marshal(SessionResponse{SessionID: sessions.create()})
+		if err != nil {
+			http.Error(w, "Error creating response", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write(jsonData)
+	}
+}
+
+// deleteSession answers DELETE /api/session/{id} with 204 once the
+// session is gone, or 404 when there was no such session.
+func deleteSession(sessions *sessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		id := req.PathValue("id")
+		if !sessions.delete(id) {
+			http.Error(w, string(stringToJson("", []ErrorDetail{{Message: sessionNotFoundMessage(id)}}, true)), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,72 @@
+package ast
+
+import (
+	"bananaScript/token"
+	"encoding/json"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarshalJSON encodes the program as a tree with one object per node. An
+// object names its node type under "type", gives the line and column of
+// the node's token under "line" and "col", and holds the node's other
+// fields under their names in lower camel case.
+func (p *Program) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeNode(reflect.ValueOf(p)))
+}
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	tokenType = reflect.TypeOf(token.Token{})
+)
+
+func encodeNode(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeNode(v.Elem())
+	case reflect.Slice:
+		elements := make([]any, v.Len())
+		for i := range elements {
+			elements[i] = encodeNode(v.Index(i))
+		}
+		return elements
+	case reflect.Struct:
+		return encodeStruct(v)
+	default:
+		return v.Interface()
+	}
+}
+
+func encodeStruct(v reflect.Value) map[string]any {
+	t := v.Type()
+	isNode := reflect.PointerTo(t).Implements(nodeType)
+
+	fields := map[string]any{}
+	if isNode {
+		fields["type"] = t.Name()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Type == tokenType {
+			if tok := v.Field(i).Interface().(token.Token); isNode && tok.Line > 0 {
+				fields["line"] = tok.Line
+				fields["col"] = tok.Column
+			}
+			continue
+		}
+		fields[lowerFirst(field.Name)] = encodeNode(v.Field(i))
+	}
+	return fields
+}
+
+func lowerFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
@@ -0,0 +1,42 @@
+package ast
+
+import (
+	"bananaScript/token"
+	"encoding/json"
+	"testing"
+)
+
+func TestProgramMarshalJSON(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let", Line: 1, Column: 1},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "myVar", Line: 1, Column: 5},
+					Value: "myVar",
+				},
+				Value: &ArrayLiteral{
+					Token: token.Token{Type: token.LBRACKET, Literal: "[", Line: 2, Column: 3},
+					Elements: []Expression{
+						&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+						&Boolean{Token: token.Token{Type: token.TRUE, Literal: "true"}, Value: true},
+					},
+				},
+			},
+			&ReturnStatement{Token: token.Token{Type: token.RETURN, Literal: "return"}},
+		},
+	}
+
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatalf("could not marshal program: %v", err)
+	}
+
+	expected := `{"statements":[` +
+		`{"col":1,"line":1,"name":{"col":5,"line":1,"type":"Identifier","value":"myVar"},"type":"LetStatement",` +
+		`"value":{"col":3,"elements":[{"type":"IntegerLiteral","value":1},{"type":"Boolean","value":true}],"line":2,"type":"ArrayLiteral"}},` +
+		`{"returnValue":null,"type":"ReturnStatement"}],"type":"Program"}`
+	if string(data) != expected {
+		t.Errorf("wrong JSON.\nexpected=%s\ngot=     %s", expected, data)
+	}
+}
@@ -2,9 +2,17 @@ package evaluator
 
 import (
 	"bananaScript/object"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
 )
 
+// maxRangeLength caps the arrays range builds, so that a huge range fails
+// instead of exhausting memory.
+var maxRangeLength = 1_000_000
+
 var builtins = map[string]*object.Builtin{
 	"len": {
 		Fn: func(args ...object.Object) object.Object {
@@ -17,7 +25,13 @@ var builtins = map[string]*object.Builtin{
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+			case *object.Tuple:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Set:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
@@ -25,13 +39,37 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"print": {
-		Fn: func(args ...object.Object) object.Object {
+		EnvFn: func(env *object.Environment, args ...object.Object) object.Object {
+			return printArgs(env, args, "")
+		},
+	},
+	"println": {
+		EnvFn: func(env *object.Environment, args ...object.Object) object.Object {
+			return printArgs(env, args, "\n")
+		},
+	},
+	"puts": {
+		EnvFn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return printArgs(env, nil, "\n")
+			}
 			for _, arg := range args {
-				log.Println(arg.Inspect())
+				if errObj := printArgs(env, []object.Object{arg}, "\n"); errObj != NULL {
+					return errObj
+				}
 			}
 			return NULL
 		},
 	},
+	"input": {
+		EnvFn: func(env *object.Environment, args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1",
+					len(args))
+			}
+			return readInput(env, args)
+		},
+	},
 	"first": {
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 1 {
@@ -94,9 +132,41 @@ var builtins = map[string]*object.Builtin{
 				return &object.Array{Elements: newElements}
 			}
 
-			return NULL
+			return &object.Array{Elements: []object.Object{}}
+		},
+	},
+	// reverse returns a reversed copy of an array, or of a string rune by
+	// rune.
+	"reverse": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Array:
+				length := len(arg.Elements)
+				newElements := make([]object.Object, length)
+				for i, el := range arg.Elements {
+					newElements[length-1-i] = el
+				}
+				return &object.Array{Elements: newElements}
+			case *object.String:
+				runes := []rune(arg.Value)
+				for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+					runes[i], runes[j] = runes[j], runes[i]
+				}
+				return &object.String{Value: string(runes)}
+			default:
+				return newError("argument to `reverse` must be ARRAY or STRING, got %s",
+					args[0].Type())
+			}
 		},
 	},
+	// The array builtins change the array they are given, so every name
+	// bound to it sees the change. push, unshift and insert return the
+	// array, and pop and shift the element they removed.
 	"push": {
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 2 {
@@ -109,13 +179,396 @@ var builtins = map[string]*object.Builtin{
 					args[0].Type())
 			}
 
+			arr := args[0].(*object.Array)
+			arr.Elements = append(arr.Elements, args[1])
+			return arr
+		},
+	},
+	"pop": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("argument to `pop` must be ARRAY, got %s",
+					args[0].Type())
+			}
+
 			arr := args[0].(*object.Array)
 			length := len(arr.Elements)
-			newElements := make([]object.Object, length+1)
-			copy(newElements, arr.Elements)
-			newElements[length] = args[1]
+			if length == 0 {
+				return newError("cannot pop from an empty array")
+			}
 
-			return &object.Array{Elements: newElements}
+			last := arr.Elements[length-1]
+			arr.Elements[length-1] = nil
+			arr.Elements = arr.Elements[:length-1]
+			return last
 		},
 	},
+	"unshift": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("argument to `unshift` must be ARRAY, got %s",
+					args[0].Type())
+			}
+
+			arr := args[0].(*object.Array)
+			arr.Elements = insertElement(arr.Elements, 0, args[1])
+			return arr
+		},
+	},
+	"shift": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			if args[0].Type() != object.ARRAY_OBJ {
+				return newError("argument to `shift` must be ARRAY, got %s",
+					args[0].Type())
+			}
+
+			arr := args[0].(*object.Array)
+			length := len(arr.Elements)
+			if length == 0 {
+				return newError("cannot shift from an empty array")
+			}
+
+			first := arr.Elements[0]
+			copy(arr.Elements, arr.Elements[1:])
+			arr.Elements[length-1] = nil
+			arr.Elements = arr.Elements[:length-1]
+			return first
+		},
+	},
+	// insert puts value at index, moving the elements from there on up
+	// by one. An index equal to the length appends.
+	"insert": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3",
+					len(args))
+			}
+
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("first argument to `insert` must be ARRAY, got %s",
+					args[0].Type())
+			}
+			index, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("second argument to `insert` must be INTEGER, got %s",
+					args[1].Type())
+			}
+			if index.Value < 0 || index.Value > int64(len(arr.Elements)) {
+				return newError("index out of range: %d (array length %d)",
+					index.Value, len(arr.Elements))
+			}
+
+			arr.Elements = insertElement(arr.Elements, int(index.Value), args[2])
+			return arr
+		},
+	},
+	"substr": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=3",
+					len(args))
+			}
+
+			if args[0].Type() != object.STRING_OBJ {
+				return newError("first argument to `substr` must be STRING, got %s",
+					args[0].Type())
+			}
+			if args[1].Type() != object.INTEGER_OBJ || args[2].Type() != object.INTEGER_OBJ {
+				return newError("start and length arguments to `substr` must be INTEGER, got %s and %s",
+					args[1].Type(), args[2].Type())
+			}
+
+			runes := []rune(args[0].(*object.String).Value)
+			start := args[1].(*object.Integer).Value
+			length := args[2].(*object.Integer).Value
+			if start < 0 || length < 0 {
+				return newError("start and length arguments to `substr` must not be negative, got %d and %d",
+					start, length)
+			}
+
+			// Clamp to the string, so substr("abc", 1, 10) returns "bc".
+			end := start + length
+			if start > int64(len(runes)) {
+				start = int64(len(runes))
+			}
+			if end > int64(len(runes)) || end < start {
+				end = int64(len(runes))
+			}
+
+			return &object.String{Value: string(runes[start:end])}
+		},
+	},
+	// range(stop), range(start, stop) and range(start, stop, step) count
+	// from start, 0 by default, up to but not including stop.
+	"range": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1 to 3",
+					len(args))
+			}
+
+			bounds := make([]int64, len(args))
+			for i, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("arguments to `range` must be INTEGER, got %s",
+						arg.Type())
+				}
+				bounds[i] = integer.Value
+			}
+
+			start, stop, step := int64(0), bounds[0], int64(1)
+			if len(bounds) > 1 {
+				start, stop = bounds[0], bounds[1]
+			}
+			if len(bounds) > 2 {
+				step = bounds[2]
+			}
+			if step == 0 {
+				return newError("step argument to `range` must not be zero")
+			}
+			if (step > 0 && start > stop) || (step < 0 && start < stop) {
+				return newError("range(%d, %d, %d) never reaches its stop", start, stop, step)
+			}
+
+			// The distance is computed unsigned, so that it cannot overflow
+			// however far apart start and stop are.
+			var count uint64
+			switch {
+			case step > 0 && start < stop:
+				count = (uint64(stop-start)-1)/uint64(step) + 1
+			case step < 0 && start > stop:
+				count = (uint64(start-stop)-1)/uint64(-step) + 1
+			}
+			if count > uint64(maxRangeLength) {
+				return newError("range would exceed %d elements", maxRangeLength)
+			}
+
+			elements := make([]object.Object, count)
+			for i := range elements {
+				elements[i] = &object.Integer{Value: start + int64(i)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"keys": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("argument to `keys` must be HASH, got %s",
+					args[0].Type())
+			}
+
+			pairs := hash.Pairs()
+			keys := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				keys[i] = pair.Key
+			}
+			return &object.Array{Elements: keys}
+		},
+	},
+	// delete returns a copy of the hash without the key, in the same order,
+	// and leaves the hash itself untouched. A missing key is not an error.
+	"delete": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("first argument to `delete` must be HASH, got %s",
+					args[0].Type())
+			}
+			if _, ok := object.AsHashable(args[1]); !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+
+			copied := object.NewHash()
+			for _, pair := range hash.Pairs() {
+				copied.Set(pair.Key, pair.Value)
+			}
+			copied.Delete(args[1])
+			return copied
+		},
+	},
+	"values": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("argument to `values` must be HASH, got %s",
+					args[0].Type())
+			}
+
+			pairs := hash.Pairs()
+			values := make([]object.Object, len(pairs))
+			for i, pair := range pairs {
+				values[i] = pair.Value
+			}
+			return &object.Array{Elements: values}
+		},
+	},
+	"has": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("first argument to `has` must be HASH, got %s",
+					args[0].Type())
+			}
+			if _, ok := object.AsHashable(args[1]); !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+
+			_, ok = hash.Get(args[1])
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+	// error raises its message like throw does: the error it returns
+	// propagates from the call.
+	"error": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+			if str, ok := args[0].(*object.String); ok {
+				return newError("%s", str.Value)
+			}
+			return newError("%s", args[0].Inspect())
+		},
+	},
+	"assert": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+
+			message := "assertion failed"
+			if len(args) == 2 {
+				str, ok := args[1].(*object.String)
+				if !ok {
+					return newError("second argument to `assert` must be STRING, got %s",
+						args[1].Type())
+				}
+				message = str.Value
+			}
+
+			if !isTruthy(args[0]) {
+				return newError("%s", message)
+			}
+			return NULL
+		},
+	},
+	"split":            {Fn: stringSplit},
+	"join":             {Fn: stringJoin},
+	"trim":             {Fn: stringTrim},
+	"upper":            {Fn: stringUpper},
+	"lower":            {Fn: stringLower},
+	"contains":         {Fn: stringContains},
+	"startsWith":       {Fn: stringStartsWith},
+	"endsWith":         {Fn: stringEndsWith},
+	"replace":          {Fn: stringReplace},
+	"repeat":           {Fn: stringRepeat},
+	"int":              {Fn: convertInt},
+	"float":            {Fn: convertFloat},
+	"str":              {Fn: convertStr},
+	"bool":             {Fn: convertBool},
+	"type":             {Fn: typeName},
+	"time":             {Fn: timeNow},
+	"sleep":            {EnvFn: timeSleep},
+	"abs":              {Fn: mathAbs},
+	"sqrt":             {Fn: mathSqrt},
+	"pow":              {Fn: mathPow},
+	"floor":            {Fn: mathFloor},
+	"ceil":             {Fn: mathCeil},
+	"min":              {Fn: mathMin},
+	"max":              {Fn: mathMax},
+	"set":              {Fn: setNew},
+	"set_add":          {Fn: setAdd},
+	"set_remove":       {Fn: setRemove},
+	"set_union":        {Fn: setUnion},
+	"set_intersection": {Fn: setIntersection},
+	"set_difference":   {Fn: setDifference},
+	"tomlParse":        {Fn: tomlParse},
+	"tomlStringify":    {Fn: tomlStringify},
+	"yamlParse":        {Fn: yamlParse},
+	"yamlStringify":    {Fn: yamlStringify},
+}
+
+// insertElement returns elements with value inserted at index, reusing
+// the backing array when it has room.
+func insertElement(elements []object.Object, index int, value object.Object) []object.Object {
+	elements = append(elements, nil)
+	copy(elements[index+1:], elements[index:])
+	elements[index] = value
+	return elements
+}
+
+// readInput prints the optional prompt and returns the next line of the
+// run's input without its line ending, or null once the input is used up.
+func readInput(env *object.Environment, prompt []object.Object) object.Object {
+	in := stateOf(env).in
+	if in == nil {
+		return newError("input() is not available in API mode")
+	}
+	if errObj := printArgs(env, prompt, ""); errObj != NULL {
+		return errObj
+	}
+
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		if errors.Is(err, io.EOF) {
+			return NULL
+		}
+		return newError("could not read input: %s", err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return &object.String{Value: line}
+}
+
+// printArgs writes args to the output of the run env belongs to, separated
+// by spaces and followed by end.
+func printArgs(env *object.Environment, args []object.Object, end string) object.Object {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Inspect()
+	}
+	if _, err := fmt.Fprint(stateOf(env).out, strings.Join(parts, " ")+end); err != nil {
+		return newError("could not print: %s", err)
+	}
+	return NULL
 }
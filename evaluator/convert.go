@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// convertInt truncates a Float, parses a decimal String, ignoring the
+// whitespace around it, and turns true and false into 1 and 0.
+func convertInt(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return arg
+	case *object.Float:
+		value := math.Trunc(arg.Value)
+		// -2^63 is exact as a float64, but 2^63 is already out of range.
+		if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+			return newError("cannot convert %s to INTEGER: out of range", arg.Inspect())
+		}
+		return &object.Integer{Value: int64(value)}
+	case *object.String:
+		value, err := strconv.ParseInt(strings.TrimSpace(arg.Value), 10, 64)
+		if err != nil {
+			return newError("cannot convert %q to INTEGER", arg.Value)
+		}
+		return &object.Integer{Value: value}
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	default:
+		return newError("cannot convert %s to INTEGER", arg.Type())
+	}
+}
+
+// convertFloat parses a String, ignoring the whitespace around it, and
+// widens an Integer.
+func convertFloat(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Float:
+		return arg
+	case *object.Integer:
+		return &object.Float{Value: float64(arg.Value)}
+	case *object.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+		if err != nil {
+			return newError("cannot convert %q to FLOAT", arg.Value)
+		}
+		return &object.Float{Value: value}
+	default:
+		return newError("cannot convert %s to FLOAT", arg.Type())
+	}
+}
+
+// convertStr returns what the REPL prints for any value.
+func convertStr(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	if str, ok := args[0].(*object.String); ok {
+		return str
+	}
+	return &object.String{Value: args[0].Inspect()}
+}
+
+// convertBool follows the truthiness rules of if and while.
+func convertBool(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	return nativeBoolToBooleanObject(isTruthy(args[0]))
+}
+
+// typeName returns the name of the type of any value, as errors spell it.
+func typeName(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	return &object.String{Value: string(args[0].Type())}
+}
@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestConversionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`int("42")`, 42},
+		{`int("-7")`, -7},
+		{"int(3.9)", 3},
+		{"int(-3.9)", -3},
+		{"int(true)", 1},
+		{"int(false)", 0},
+		{"int(5)", 5},
+		{"int(str(42)) == 42", true},
+		{`int("  12 ")`, 12},
+		{`int("\t-7\n")`, -7},
+		{`float(" 2.5 ")`, 2.5},
+		{`"total: " + str(42)`, "total: 42"},
+		{`str({"a": [1, "b"]})`, "{a: [1, b]}"},
+		{`try { int("abc") } catch (e) { "bad number: " + e }`, `bad number: cannot convert "abc" to INTEGER`},
+		{`float("2.5")`, 2.5},
+		{"float(3)", 3.0},
+		{`float("1e3")`, 1000.0},
+		{"float(str(0.25)) == 0.25", true},
+		{"str(42)", "42"},
+		{"str(2.5)", "2.5"},
+		{"str(true)", "true"},
+		{"str(null)", "null"},
+		{`str("hi")`, "hi"},
+		{"str([1, 2])", "[1, 2]"},
+		{`str(1) + "!"`, "1!"},
+		{"bool(0)", true},
+		{`bool("")`, true},
+		{"bool(null)", false},
+		{"bool(false)", false},
+		{"bool([])", true},
+		{"bool(bool(null)) == false", true},
+		{"type(1)", "INTEGER"},
+		{"type(1.5)", "FLOAT"},
+		{`type("a")`, "STRING"},
+		{"type(true)", "BOOLEAN"},
+		{"type(null)", "NULL"},
+		{"type([])", "ARRAY"},
+		{"type({})", "HASH"},
+		{"type((1, 2))", "TUPLE"},
+		{"type(set())", "SET"},
+		{"type(fn() {})", "FUNCTION"},
+		{"type(type)", "BUILTIN"},
+		{"type(type(1))", "STRING"},
+		{`let describe = fn(x) { match (type(x)) { "INTEGER" => "int", _ => "other" } }; describe(3)`, "int"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestConversionBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`int("abc")`, `cannot convert "abc" to INTEGER`},
+		{`int("4.5")`, `cannot convert "4.5" to INTEGER`},
+		{`int("")`, `cannot convert "" to INTEGER`},
+		{`int("   ")`, `cannot convert "   " to INTEGER`},
+		{`int("1 2")`, `cannot convert "1 2" to INTEGER`},
+		{`int("99999999999999999999")`, `cannot convert "99999999999999999999" to INTEGER`},
+		{"int(2.0 ^ 63)", "cannot convert 9223372036854776000 to INTEGER: out of range"},
+		{"int(null)", "cannot convert NULL to INTEGER"},
+		{`float("x")`, `cannot convert "x" to FLOAT`},
+		{"float(true)", "cannot convert BOOLEAN to FLOAT"},
+		{"str()", "wrong number of arguments. got=0, want=1"},
+		{"bool(1, 2)", "wrong number of arguments. got=2, want=1"},
+		{"type()", "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %s. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
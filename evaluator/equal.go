@@ -0,0 +1,90 @@
+package evaluator
+
+import "bananaScript/object"
+
+// comparison is a pair of containers being compared by objectsEqual.
+type comparison struct {
+	left, right object.Object
+}
+
+// objectsEqual reports whether left and right are structurally equal:
+// arrays and tuples element by element, hashes by their keys and the
+// values under them, and sets by their elements. Numbers and strings
+// compare by value, and anything else only equals itself, so values of
+// different types are never equal.
+//
+// inProgress holds the containers already being compared further up, so
+// that a structure containing itself compares without recursing forever:
+// meeting the same pair again adds no difference, so it counts as equal.
+func objectsEqual(left, right object.Object, inProgress map[comparison]bool) bool {
+	if left == right {
+		return true
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		right, ok := right.(*object.Array)
+		if !ok || len(left.Elements) != len(right.Elements) {
+			return false
+		}
+		return elementsEqual(left, right, left.Elements, right.Elements, inProgress)
+	case *object.Tuple:
+		right, ok := right.(*object.Tuple)
+		if !ok || len(left.Elements) != len(right.Elements) {
+			return false
+		}
+		return elementsEqual(left, right, left.Elements, right.Elements, inProgress)
+	case *object.Hash:
+		right, ok := right.(*object.Hash)
+		if !ok || left.Len() != right.Len() {
+			return false
+		}
+		pair := comparison{left, right}
+		if inProgress[pair] {
+			return true
+		}
+		inProgress[pair] = true
+		defer delete(inProgress, pair)
+
+		for _, kv := range left.Pairs() {
+			value, ok := right.Get(kv.Key)
+			if !ok || !objectsEqual(kv.Value, value, inProgress) {
+				return false
+			}
+		}
+		return true
+	case *object.Set:
+		right, ok := right.(*object.Set)
+		if !ok || left.Len() != right.Len() {
+			return false
+		}
+		for _, element := range left.Elements() {
+			if !right.Has(element) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if isNumber(left) && isNumber(right) ||
+		left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ {
+		return evalInfixExpression("==", left, right) == TRUE
+	}
+	return false
+}
+
+func elementsEqual(left, right object.Object, leftElements, rightElements []object.Object, inProgress map[comparison]bool) bool {
+	pair := comparison{left, right}
+	if inProgress[pair] {
+		return true
+	}
+	inProgress[pair] = true
+	defer delete(inProgress, pair)
+
+	for i := range leftElements {
+		if !objectsEqual(leftElements[i], rightElements[i], inProgress) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,15 +3,25 @@ package evaluator
 import (
 	"bananaScript/ast"
 	"bananaScript/object"
+	"bananaScript/token"
 	"fmt"
+	"math"
+	"strings"
 )
 
 var (
 	NULL  = &object.Null{}
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
+
+	BREAK    = &object.BreakValue{}
+	CONTINUE = &object.ContinueValue{}
 )
 
+// maxLoopIterations bounds every loop so that `while (true) {}` fails with
+// an error instead of hanging the REPL or the API server.
+var maxLoopIterations = 1000000
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -43,21 +53,36 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
 
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 
+	case *ast.NullLiteral:
+		return NULL
+
 	case *ast.AssignmentExpression:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if err := env.Assign(node.Name.Value, val); err != nil {
+			return newError("%s", err)
+		}
 		return val
 
 	case *ast.PrefixExpression:
+		if node.Operator == "++" || node.Operator == "--" {
+			return evalUpdateExpression(node.Operator, node.Right, true, env)
+		}
+
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
@@ -65,17 +90,31 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return evalPrefixExpression(node.Operator, right)
 
+	case *ast.PostfixExpression:
+		return evalUpdateExpression(node.Operator, node.Left, false, env)
+
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
 
+		// ?? only evaluates its right side when the left one is null.
+		if node.Operator == "??" && left.Type() != object.NULL_OBJ {
+			return left
+		}
+
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 
+		switch node.Operator {
+		case "??":
+			return right
+		case "in":
+			return evalInExpression(left, right)
+		}
 		return evalInfixExpression(node.Operator, left, right)
 
 	case *ast.BlockStatement:
@@ -84,6 +123,24 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.TernaryExpression:
+		return evalTernaryExpression(node, env)
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+
+	case *ast.ThrowStatement:
+		return evalThrowStatement(node, env)
+
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+
+	case *ast.ImportExpression:
+		return evalImportExpression(node, env)
+
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
 		if isError(val) {
@@ -92,17 +149,43 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 		return &object.ReturnValue{Value: val}
 
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	case *ast.DoWhileStatement:
+		return evalDoWhileStatement(node, env)
+
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if errObj := declare(node.Token, node.Name.Value, val, env); errObj != nil {
+			return errObj
+		}
+
+	case *ast.DestructuringLetStatement:
+		return evalDestructuringLetStatement(node, env)
 
 	case *ast.FunctionLiteral:
 		params := node.Parameters
 		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{
+			Parameters: params,
+			Defaults:   node.Defaults,
+			Variadic:   node.Variadic,
+			Env:        env,
+			Body:       body,
+		}
 
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
@@ -118,7 +201,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		return applyFunction(function, args, env)
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
@@ -127,6 +210,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.Array{Elements: elements}
 
+	case *ast.TupleLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Tuple{Elements: elements}
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -138,6 +231,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return evalIndexExpression(left, index)
 
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+
+	case *ast.IndexAssignmentExpression:
+		return evalIndexAssignmentExpression(node, env)
+
 	case *ast.CommentExpression:
 		// Comments are ignored in evaluation, so we return NULL
 		return NULL
@@ -148,6 +247,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
+	ensureState(env)
 
 	for _, stmt := range stmts {
 		result = Eval(stmt, env)
@@ -157,21 +257,27 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.BreakValue, *object.ContinueValue:
+			return loopControlError(result)
 		}
 	}
 
 	return result
 }
 
-func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+// evalBlockStatement runs the block in a scope of its own, so bindings
+// declared inside an if, loop or function body do not leak out of it.
+func evalBlockStatement(block *ast.BlockStatement, outer *object.Environment) object.Object {
 	var result object.Object
+	env := object.NewEnclosedEnvironment(outer)
 
 	for _, statement := range block.Statements {
 		result = Eval(statement, env)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_VALUE_OBJ || rt == object.CONTINUE_VALUE_OBJ {
 				return result
 			}
 		}
@@ -180,6 +286,232 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	return result
 }
 
+// evalForStatement runs Init once in a scope of its own, so loop variables
+// do not leak, and then evaluates Body in a fresh inner scope for every
+// iteration in which Condition holds, running Post after each one.
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	loopEnv := object.NewEnclosedEnvironment(env)
+
+	if fs.Init != nil {
+		init := Eval(fs.Init, loopEnv)
+		if isError(init) {
+			return init
+		}
+	}
+
+	for iterations := 0; ; iterations++ {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				break
+			}
+		}
+
+		if iterations == maxLoopIterations {
+			return loopLimitError()
+		}
+		if errObj := checkCancelled(env); errObj != nil {
+			return errObj
+		}
+
+		result := evalBlockStatement(fs.Body, loopEnv)
+		if result != nil {
+			switch result.Type() {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+				return result
+			case object.BREAK_VALUE_OBJ:
+				return NULL
+			}
+		}
+
+		if fs.Post != nil {
+			post := Eval(fs.Post, loopEnv)
+			if isError(post) {
+				return post
+			}
+		}
+	}
+
+	return NULL
+}
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for iterations := 0; ; iterations++ {
+		condition := Eval(ws.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if iterations == maxLoopIterations {
+			return loopLimitError()
+		}
+		if errObj := checkCancelled(env); errObj != nil {
+			return errObj
+		}
+
+		result := evalBlockStatement(ws.Body, env)
+		if result != nil {
+			switch result.Type() {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+				return result
+			case object.BREAK_VALUE_OBJ:
+				return NULL
+			}
+		}
+	}
+}
+
+// evalDoWhileStatement runs Body once before Condition is first checked.
+func evalDoWhileStatement(ds *ast.DoWhileStatement, env *object.Environment) object.Object {
+	for iterations := 0; ; iterations++ {
+		if iterations == maxLoopIterations {
+			return loopLimitError()
+		}
+		if errObj := checkCancelled(env); errObj != nil {
+			return errObj
+		}
+
+		result := evalBlockStatement(ds.Body, env)
+		if result != nil {
+			switch result.Type() {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+				return result
+			case object.BREAK_VALUE_OBJ:
+				return NULL
+			}
+		}
+
+		condition := Eval(ds.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+	}
+}
+
+func loopLimitError() *object.Error {
+	return newFatalError("loop exceeded the maximum of %d iterations", maxLoopIterations)
+}
+
+// declare binds name in the current scope for a let or const statement.
+func declare(tok token.Token, name string, val object.Object, env *object.Environment) *object.Error {
+	var err error
+	switch {
+	case tok.Type != token.CONST:
+		err = env.Declare(name, val)
+	case env.IsConstant(name) && !env.IsConstantInScope(name):
+		// A let may shadow an outer constant, but a const may not: two
+		// constants of the same name would make either one ambiguous.
+		return newError("cannot shadow constant '%s'", name)
+	default:
+		err = env.DeclareConst(name, val)
+	}
+	if err != nil {
+		return newError("%s", err)
+	}
+	return nil
+}
+
+// evalDestructuringLetStatement binds the elements of a tuple or array to
+// the names of a `let a, b = ...` statement, one element per name.
+func evalDestructuringLetStatement(ds *ast.DestructuringLetStatement, env *object.Environment) object.Object {
+	val := Eval(ds.Value, env)
+	if isError(val) {
+		return val
+	}
+	if ds.Array {
+		return evalArrayDestructuring(ds, val, env)
+	}
+
+	var elements []object.Object
+	switch val := val.(type) {
+	case *object.Tuple:
+		elements = val.Elements
+	case *object.Array:
+		elements = val.Elements
+	default:
+		return newError("cannot destructure %s into %d names", val.Type(), len(ds.Names))
+	}
+	if len(elements) != len(ds.Names) {
+		return newError("cannot destructure %d values into %d names", len(elements), len(ds.Names))
+	}
+	return bindDestructured(ds, elements, env)
+}
+
+// evalArrayDestructuring binds the names of a `let [a, b] = arr` statement
+// to the elements of arr by position. Names past the end of arr are bound
+// to null and elements past the last name are ignored, unless a `...rest`
+// name collects them into a new array.
+func evalArrayDestructuring(ds *ast.DestructuringLetStatement, val object.Object, env *object.Environment) object.Object {
+	arr, ok := val.(*object.Array)
+	if !ok {
+		return newError("cannot destructure %s with an array pattern", val.Type())
+	}
+
+	positional := len(ds.Names)
+	if ds.Rest {
+		positional--
+	}
+	values := make([]object.Object, 0, len(ds.Names))
+	for i := 0; i < positional; i++ {
+		var element object.Object = NULL
+		if i < len(arr.Elements) {
+			element = arr.Elements[i]
+		}
+		values = append(values, element)
+	}
+
+	if ds.Rest {
+		rest := []object.Object{}
+		if positional < len(arr.Elements) {
+			rest = append(rest, arr.Elements[positional:]...)
+		}
+		values = append(values, &object.Array{Elements: rest})
+	}
+	return bindDestructured(ds, values, env)
+}
+
+// bindDestructured binds each of the names of ds to the value at the same
+// position. Unlike a plain let, a destructuring let may rebind names the
+// current scope already declared, so that `let a, b = b, a` swaps them,
+// though never a constant or a name given twice. Every value has been
+// computed by then, and no name is bound when one of them is refused.
+func bindDestructured(ds *ast.DestructuringLetStatement, values []object.Object, env *object.Environment) object.Object {
+	if ds.Token.Type == token.CONST {
+		for i, name := range ds.Names {
+			if errObj := declare(ds.Token, name.Value, values[i], env); errObj != nil {
+				return errObj
+			}
+		}
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for _, name := range ds.Names {
+		if seen[name.Value] {
+			return newError("identifier '%s' has already been declared", name.Value)
+		}
+		seen[name.Value] = true
+		if env.IsConstantInScope(name.Value) {
+			return newError("cannot reassign constant '%s'", name.Value)
+		}
+	}
+	for i, name := range ds.Names {
+		if err := env.Rebind(name.Value, values[i]); err != nil {
+			return newError("%s", err)
+		}
+	}
+	return nil
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	if isError(condition) {
@@ -194,6 +526,96 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+// evalTryExpression evaluates the try block, handing an error it produces
+// to the catch block with the error bound to the catch parameter. The
+// finally block runs afterwards whatever happened; a return, break or
+// error from finally takes the place of the try's own result. Fatal
+// errors end the run, so they skip both catch and finally.
+func evalTryExpression(te *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(te.Block, env)
+
+	if errObj, ok := result.(*object.Error); ok && errObj.Fatal {
+		return errObj
+	}
+
+	// The catch binds the message rather than the error itself, which
+	// would propagate again as soon as it was used. `throw e` raises it
+	// again with the same message.
+	if errObj, ok := result.(*object.Error); ok && te.Catch != nil {
+		catchEnv := object.NewEnclosedEnvironment(env)
+		catchEnv.Declare(te.Param.Value, &object.String{Value: errObj.Message})
+		result = Eval(te.Catch, catchEnv)
+	}
+
+	if te.Finally != nil {
+		finally := Eval(te.Finally, env)
+		if finally != nil {
+			switch finally.Type() {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ,
+				object.BREAK_VALUE_OBJ, object.CONTINUE_VALUE_OBJ:
+				return finally
+			}
+		}
+	}
+
+	return result
+}
+
+// evalThrowStatement raises its value as an error. An error is raised as
+// it is, a string becomes the message, and anything else is inspected.
+func evalThrowStatement(ts *ast.ThrowStatement, env *object.Environment) object.Object {
+	val := Eval(ts.Value, env)
+	switch val := val.(type) {
+	case *object.Error:
+		return val
+	case *object.String:
+		return newError("%s", val.Value)
+	default:
+		return newError("%s", val.Inspect())
+	}
+}
+
+func evalTernaryExpression(te *ast.TernaryExpression, env *object.Environment) object.Object {
+	condition := Eval(te.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+	if isTruthy(condition) {
+		return Eval(te.Consequence, env)
+	}
+	return Eval(te.Alternative, env)
+}
+
+// evalMatchExpression evaluates the value of the first arm whose pattern
+// equals the subject, or of the `_` arm, trying patterns in order and
+// evaluating only as many as needed. It is null when no arm matches.
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(me.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		if arm.Pattern == nil {
+			return Eval(arm.Value, env)
+		}
+
+		pattern := Eval(arm.Pattern, env)
+		if isError(pattern) {
+			return pattern
+		}
+		matched := evalInfixExpression("==", subject, pattern)
+		if isError(matched) {
+			return matched
+		}
+		if matched == TRUE {
+			return Eval(arm.Value, env)
+		}
+	}
+
+	return NULL
+}
+
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	if input {
 		return TRUE
@@ -207,17 +629,33 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 		return evalMinusPrefixOperatorExpression(right)
 	case "!":
 		return evalBangOperatorExpression(right)
+	case "~":
+		return evalBitwiseNotOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return newError("integer overflow: -(%d)", right.Value)
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
+
+func evalBitwiseNotOperatorExpression(right object.Object) object.Object {
+	if right.Type() != object.INTEGER_OBJ {
+		return newError("unknown operator: ~%s", right.Type())
+	}
 	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return &object.Integer{Value: ^value}
 }
 
 func evalBangOperatorExpression(right object.Object) object.Object {
@@ -233,6 +671,52 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 	}
 }
 
+// evalUpdateExpression applies ++ or -- to the binding named by target.
+// The prefix form yields the updated value, the postfix form the old one.
+func evalUpdateExpression(
+	operator string,
+	target ast.Expression,
+	prefix bool,
+	env *object.Environment,
+) object.Object {
+	ident, ok := target.(*ast.Identifier)
+	if !ok {
+		return newError("invalid operand for %s: %s", operator, target.String())
+	}
+
+	current := evalIdentifier(ident, env)
+	if isError(current) {
+		return current
+	}
+
+	integer, ok := current.(*object.Integer)
+	if !ok {
+		if prefix {
+			return newError("unknown operator: %s%s", operator, current.Type())
+		}
+		return newError("unknown operator: %s%s", current.Type(), operator)
+	}
+
+	delta := int64(1)
+	if operator == "--" {
+		delta = -1
+	}
+
+	value, ok := addInt64(integer.Value, delta)
+	if !ok {
+		return newError("integer overflow: %s on %d", operator, integer.Value)
+	}
+	updated := &object.Integer{Value: value}
+	if err := env.Assign(ident.Value, updated); err != nil {
+		return newError("%s", err)
+	}
+
+	if prefix {
+		return updated
+	}
+	return integer
+}
+
 func evalInfixExpression(
 	operator string,
 	left, right object.Object,
@@ -240,12 +724,20 @@ func evalInfixExpression(
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case operator == "*" && left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ:
+		return repeatString(left.(*object.String).Value, right.(*object.Integer).Value)
+	case operator == "*" && left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ:
+		return repeatString(right.(*object.String).Value, left.(*object.Integer).Value)
+	case operator == "+" && left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ:
+		return concatArrays(left.(*object.Array), right.(*object.Array))
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
+		return nativeBoolToBooleanObject(objectsEqual(left, right, map[comparison]bool{}))
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return nativeBoolToBooleanObject(!objectsEqual(left, right, map[comparison]bool{}))
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -253,23 +745,151 @@ func evalInfixExpression(
 	}
 }
 
+// evalInExpression reports whether needle is an element of an array or
+// tuple, a key of a hash, a member of a set or a substring of a string.
+func evalInExpression(needle, haystack object.Object) object.Object {
+	switch haystack := haystack.(type) {
+	case *object.Array:
+		return nativeBoolToBooleanObject(containsObject(haystack.Elements, needle))
+	case *object.Tuple:
+		return nativeBoolToBooleanObject(containsObject(haystack.Elements, needle))
+	case *object.Hash:
+		if _, ok := object.AsHashable(needle); !ok {
+			return newError("unusable as hash key: %s", needle.Type())
+		}
+		_, ok := haystack.Get(needle)
+		return nativeBoolToBooleanObject(ok)
+	case *object.Set:
+		if _, ok := object.AsHashable(needle); !ok {
+			return newError("unusable as set element: %s", needle.Type())
+		}
+		return nativeBoolToBooleanObject(haystack.Has(needle))
+	case *object.String:
+		str, ok := needle.(*object.String)
+		if !ok {
+			return newError("type mismatch: %s in STRING", needle.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(haystack.Value, str.Value))
+	default:
+		return newError("unknown operator: %s in %s", needle.Type(), haystack.Type())
+	}
+}
+
+func containsObject(elements []object.Object, needle object.Object) bool {
+	for _, el := range elements {
+		if objectsEqual(needle, el, map[comparison]bool{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// concatArrays returns a new array holding the elements of left followed
+// by those of right, sharing no storage with either.
+func concatArrays(left, right *object.Array) object.Object {
+	elements := make([]object.Object, 0, len(left.Elements)+len(right.Elements))
+	elements = append(elements, left.Elements...)
+	elements = append(elements, right.Elements...)
+	return &object.Array{Elements: elements}
+}
+
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		sum, ok := addInt64(leftVal, rightVal)
+		if !ok {
+			return newError("integer overflow: %d + %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: sum}
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		difference, ok := subtractInt64(leftVal, rightVal)
+		if !ok {
+			return newError("integer overflow: %d - %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: difference}
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		product, ok := multiplyInt64(leftVal, rightVal)
+		if !ok {
+			return newError("integer overflow: %d * %d", leftVal, rightVal)
+		}
+		return &object.Integer{Value: product}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return newError("integer overflow: %d / %d", leftVal, rightVal)
+		}
 		return &object.Integer{Value: leftVal / rightVal}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return evalPowerExpression(leftVal, rightVal)
+	case "xor":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		return evalShiftExpression(operator, leftVal, rightVal)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat promotes an Integer or Float to a float64.
+func toFloat(obj object.Object) float64 {
+	if integer, ok := obj.(*object.Integer); ok {
+		return float64(integer.Value)
+	}
+	return obj.(*object.Float).Value
+}
+
+// evalFloatInfixExpression handles arithmetic and comparisons where at
+// least one operand is a Float, promoting an Integer operand to match.
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "^":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -281,6 +901,67 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+// evalPowerExpression raises base to exp by repeated squaring, reporting an
+// error instead of silently wrapping when the result overflows an int64.
+func evalPowerExpression(base, exp int64) object.Object {
+	if exp < 0 {
+		return newError("negative exponent not supported for integers: %d ^ %d", base, exp)
+	}
+
+	result, factor, remaining := int64(1), base, exp
+	for remaining > 0 {
+		var ok bool
+		if remaining&1 == 1 {
+			if result, ok = multiplyInt64(result, factor); !ok {
+				return newError("integer overflow: %d ^ %d", base, exp)
+			}
+		}
+		remaining >>= 1
+		if remaining > 0 {
+			if factor, ok = multiplyInt64(factor, factor); !ok {
+				return newError("integer overflow: %d ^ %d", base, exp)
+			}
+		}
+	}
+
+	return &object.Integer{Value: result}
+}
+
+// addInt64 returns a + b and whether the sum fits in an int64.
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+// subtractInt64 returns a - b and whether the difference fits in an int64.
+func subtractInt64(a, b int64) (int64, bool) {
+	c := a - b
+	return c, (c < a) == (b > 0)
+}
+
+// multiplyInt64 returns a * b and whether the product fits in an int64.
+func multiplyInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (c < 0) != ((a < 0) != (b < 0)) || c/b != a {
+		return c, false
+	}
+	return c, true
+}
+
+func evalShiftExpression(operator string, leftVal, rightVal int64) object.Object {
+	if rightVal < 0 || rightVal > 63 {
+		return newError("invalid shift count: %d", rightVal)
+	}
+
+	if operator == "<<" {
+		return &object.Integer{Value: leftVal << rightVal}
+	}
+	return &object.Integer{Value: leftVal >> rightVal}
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
@@ -292,6 +973,10 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -307,6 +992,12 @@ func newError(format string, a ...any) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// newFatalError returns an error for a run that hit one of its limits,
+// which ends the run even inside try.
+func newFatalError(format string, a ...any) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Fatal: true}
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -323,54 +1014,150 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	var result []object.Object
 
 	for _, e := range exps {
+		spread, isSpread := e.(*ast.SpreadExpression)
+		if isSpread {
+			e = spread.Value
+		}
+
 		evaluated := Eval(e, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
 		}
-		result = append(result, evaluated)
+
+		if !isSpread {
+			result = append(result, evaluated)
+			continue
+		}
+		array, ok := evaluated.(*object.Array)
+		if !ok {
+			return []object.Object{newError("spread argument must be ARRAY, got %s", evaluated.Type())}
+		}
+		result = append(result, array.Elements...)
 	}
 
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+// applyFunction calls fn with args. env is the environment of the call,
+// which builtins with an EnvFn are given.
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
+		if errObj := checkCancelled(fn.Env); errObj != nil {
+			return errObj
+		}
+		depth := stateOf(fn.Env).callDepth
+		if *depth >= maxCallDepth {
+			return newFatalError("maximum call stack size exceeded")
+		}
+		*depth++
+		defer func() { *depth-- }()
+
+		extendedEnv, errObj := extendFunctionEnv(fn, args)
+		if errObj != nil {
+			return errObj
+		}
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
+		if fn.EnvFn != nil {
+			return fn.EnvFn(env, args...)
+		}
 		return fn.Fn(args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
+// extendFunctionEnv binds the arguments of a call to fn's parameters. A
+// parameter whose argument is missing or null takes its default, which is
+// evaluated in the environment fn was defined in.
 func extendFunctionEnv(
 	fn *object.Function,
 	args []object.Object,
-) *object.Environment {
+) (*object.Environment, *object.Error) {
+	params := fn.Parameters
+	if fn.Variadic {
+		params = params[:len(params)-1]
+	}
+	required := len(params)
+	for required > 0 && fn.Default(required-1) != nil {
+		required--
+	}
+
+	switch {
+	case required == len(params) && !fn.Variadic && len(args) != len(params):
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), len(params))
+	case len(args) < required:
+		return nil, newError("wrong number of arguments. got=%d, want at least %d",
+			len(args), required)
+	case !fn.Variadic && len(args) > len(params):
+		return nil, newError("wrong number of arguments. got=%d, want at most %d",
+			len(args), len(params))
+	}
+
 	env := object.NewEnclosedEnvironment(fn.Env)
 
-	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+	for paramIdx, param := range params {
+		var val object.Object = NULL
+		if paramIdx < len(args) {
+			val = args[paramIdx]
+		}
+		if def := fn.Default(paramIdx); def != nil && val.Type() == object.NULL_OBJ {
+			val = Eval(def, fn.Env)
+			if errObj, ok := val.(*object.Error); ok {
+				return nil, errObj
+			}
+		}
+		if err := env.Declare(param.Value, val); err != nil {
+			return nil, newError("%s", err)
+		}
+	}
+
+	if fn.Variadic {
+		rest := []object.Object{}
+		if len(args) > len(params) {
+			rest = append(rest, args[len(params):]...)
+		}
+		name := fn.Parameters[len(params)].Value
+		if err := env.Declare(name, &object.Array{Elements: rest}); err != nil {
+			return nil, newError("%s", err)
+		}
 	}
 
-	return env
+	return env, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.BreakValue, *object.ContinueValue:
+		// A loop never spans a function boundary, so the sentinel cannot
+		// belong to a loop in the caller.
+		return loopControlError(obj)
 	}
 
 	return obj
 }
 
+func loopControlError(obj object.Object) *object.Error {
+	return newError("%s outside of a loop", obj.Inspect())
+}
+
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalTupleIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	case left.Type() == object.SET_OBJ:
+		return evalSetIndexExpression(left, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
@@ -385,3 +1172,185 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	}
 	return arrayObject.Elements[idx]
 }
+
+func evalTupleIndexExpression(tuple, index object.Object) object.Object {
+	tupleObject := tuple.(*object.Tuple)
+	idx := index.(*object.Integer).Value
+	max := int64(len(tupleObject.Elements) - 1)
+	if idx < 0 || idx > max {
+		return NULL
+	}
+	return tupleObject.Elements[idx]
+}
+
+// evalStringIndexExpression indexes by rune rather than by byte, so that
+// multi-byte UTF-8 characters come back whole.
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Integer).Value
+	max := int64(len(runes) - 1)
+	if idx < 0 || idx > max {
+		return NULL
+	}
+	return &object.String{Value: string(runes[idx])}
+}
+
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+	for _, part := range node.Parts {
+		val := Eval(part, env)
+		if isError(val) {
+			return val
+		}
+		out.WriteString(val.Inspect())
+	}
+	return &object.String{Value: out.String()}
+}
+
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	var bounds [2]*object.Integer
+	for i, bound := range []ast.Expression{node.Start, node.End} {
+		if bound == nil {
+			continue
+		}
+		val := Eval(bound, env)
+		if isError(val) {
+			return val
+		}
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return newError("slice index must be INTEGER, got %s", val.Type())
+		}
+		bounds[i] = integer
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		start, end := sliceBounds(bounds[0], bounds[1], len(left.Elements))
+		elements := make([]object.Object, end-start)
+		copy(elements, left.Elements[start:end])
+		return &object.Array{Elements: elements}
+	case *object.Tuple:
+		start, end := sliceBounds(bounds[0], bounds[1], len(left.Elements))
+		elements := make([]object.Object, end-start)
+		copy(elements, left.Elements[start:end])
+		return &object.Tuple{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		start, end := sliceBounds(bounds[0], bounds[1], len(runes))
+		return &object.String{Value: string(runes[start:end])}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceBounds resolves optional slice bounds against length. Negative
+// bounds count from the end, out-of-range bounds are clamped, and a start
+// past the end yields an empty range.
+func sliceBounds(start, end *object.Integer, length int) (int, int) {
+	resolve := func(bound *object.Integer, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+		idx := bound.Value
+		if idx < 0 {
+			idx += int64(length)
+		}
+		if idx < 0 {
+			return 0
+		}
+		if idx > int64(length) {
+			return length
+		}
+		return int(idx)
+	}
+
+	from, to := resolve(start, 0), resolve(end, length)
+	if from > to {
+		return from, from
+	}
+	return from, to
+}
+
+// evalIndexAssignmentExpression writes value into an existing array slot or
+// a hash key in place. Arrays never grow through assignment.
+func evalIndexAssignmentExpression(node *ast.IndexAssignmentExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+	index := Eval(node.Index, env)
+	if isError(index) {
+		return index
+	}
+	value := Eval(node.Value, env)
+	if isError(value) {
+		return value
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		idx := integer.Value
+		if idx < 0 || idx >= int64(len(left.Elements)) {
+			return newError("index out of range: %d (array length %d)", idx, len(left.Elements))
+		}
+		left.Elements[idx] = value
+	case *object.Hash:
+		if !left.Set(index, value) {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+	case *object.Tuple:
+		return newError("cannot assign to an element of a TUPLE: tuples are immutable")
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+
+	return value
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		if _, ok := object.AsHashable(key); !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Set(key, value)
+	}
+
+	return hash
+}
+
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	if _, ok := object.AsHashable(index); !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	value, ok := hashObject.Get(index)
+	if !ok {
+		return NULL
+	}
+	return value
+}
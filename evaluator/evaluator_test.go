@@ -4,6 +4,8 @@ import (
 	"bananaScript/lexer"
 	"bananaScript/object"
 	"bananaScript/parser"
+	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -35,6 +37,141 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestPowerOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"2 ^ 10", 1024},
+		{"2 ^ 0", 1},
+		{"0 ^ 0", 1},
+		{"0 ^ 5", 0},
+		{"7 ^ 1", 7},
+		{"2 ^ 3 ^ 2", 512},
+		{"(2 ^ 3) ^ 2", 64},
+		{"-2 ^ 2", -4},
+		{"(-2) ^ 2", 4},
+		{"(-2) ^ 3", -8},
+		{"2 * 3 ^ 2", 18},
+		{"1 + 2 ^ 2 * 3", 13},
+		{"2 ^ 62", 4611686018427387904},
+		{"(-2) ^ 63", -9223372036854775808},
+		{"(-1) ^ 1000001", -1},
+		{"1 ^ 9223372036854775807", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestPowerOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"2 ^ -1", "negative exponent not supported for integers: 2 ^ -1"},
+		{"2 ^ 63", "integer overflow: 2 ^ 63"},
+		{"10 ^ 19", "integer overflow: 10 ^ 19"},
+		{"3 ^ 1000000", "integer overflow: 3 ^ 1000000"},
+		{`"a" ^ 2`, "type mismatch: STRING ^ INTEGER"},
+		{`"a" ^ "b"`, "unknown operator: STRING ^ STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1", "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", "integer overflow: -9223372036854775807 - 2"},
+		{"1 - -9223372036854775807", "integer overflow: 1 - -9223372036854775807"},
+		{"4611686018427387904 * 2", "integer overflow: 4611686018427387904 * 2"},
+		{"-4611686018427387904 * -2", "integer overflow: -4611686018427387904 * -2"},
+		{"let min = -9223372036854775807 - 1; min / -1", "integer overflow: -9223372036854775808 / -1"},
+		{"let min = -9223372036854775807 - 1; -min", "integer overflow: -(-9223372036854775808)"},
+		{"let x = 9223372036854775807; x++", "integer overflow: ++ on 9223372036854775807"},
+		{"let x = -9223372036854775807 - 1; --x", "integer overflow: -- on -9223372036854775808"},
+		{"let x = 9223372036854775807; x += 1", "integer overflow: 9223372036854775807 + 1"},
+		{"9223372036854775806 + 1", 9223372036854775807},
+		{"-9223372036854775807 - 1", -9223372036854775808},
+		{"-4611686018427387904 * 2", -9223372036854775808},
+		{"3037000499 * 3037000499", 9223372030926249001},
+		{"let min = -9223372036854775807 - 1; min / 1", -9223372036854775808},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned for %s. got=%T(%+v)",
+					tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q",
+					expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 xor 10", 6},
+		{"~0", -1},
+		{"~5", -6},
+		{"1 << 4", 16},
+		{"256 >> 4", 16},
+		{"-16 >> 2", -4},
+		{"1 << 63 >> 63", -1},
+		{"1 | 2 & 3", 3},
+		{"1 << 2 + 1", 8},
+		{"5 xor 5", 0},
+		{"-1 xor 0", -1},
+		{"1 | 6 xor 3", 5},
+		{"1 xor 3 & 2", 3},
+		{"~0 >> 1 << 1", -2},
+		{"(1 << 62) >> 62", 1},
+		{"1 << 0", 1},
+		{"-1 >> 63", -1},
+		{"let mask = 0xFF; mask & 0b1111", 15},
+		{"0o17 | 0x10", 31},
+		{"0x1F + 0o17 + 0b1010", 56},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -59,6 +196,124 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func testHashValue(t *testing.T, obj object.Object, key string) object.Object {
+	hash, ok := obj.(*object.Hash)
+	if !ok {
+		t.Fatalf("object is not Hash. got=%T (%+v)", obj, obj)
+	}
+
+	value, ok := hash.Get(&object.String{Value: key})
+	if !ok {
+		t.Fatalf("hash has no key %q. got=%s", key, hash.Inspect())
+	}
+	return value
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%q, want=%q",
+			result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"10 / 4.0", 2.5},
+		{"7.5 - 10", -2.5},
+		{"2 * 0.25", 0.5},
+		{"2.0 ^ 3", 8},
+		{"4 ^ 0.5", 2},
+		{"1_000.000_5", 1000.0005},
+		{"let x = 1.5; x += 1; x;", 2.5},
+		{"(1 + 2.5) * 2", 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1.0 == 1", true},
+		{"1 == 1.0", true},
+		{"0.1 + 0.2 == 0.3", false},
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"2.5 <= 2.5", true},
+		{"2.5 >= 3", false},
+		{"1.5 != 1.5", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"1 << 2.0", "unknown operator: INTEGER << FLOAT"},
+		{`1.5 + "a"`, "type mismatch: FLOAT + STRING"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{"{1.5: 1}", "unusable as hash key: FLOAT"},
+		{"let f = 1.5; f++", "unknown operator: FLOAT++"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -71,9 +326,21 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"1 < 1", false},
 		{"1 > 1", false},
 		{"1 == 1", true},
+		{"0xFF == 255", true},
+		{"0b1010 == 0o12", true},
 		{"1 != 1", false},
 		{"1 == 2", false},
 		{"1 != 2", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"3 >= 2", true},
+		{`"a" <= "b"`, true},
+		{`"b" <= "b"`, true},
+		{`"a" >= "b"`, false},
+		{`"b" >= "a"`, true},
 		{"true == true", true},
 		{"false == false", true},
 		{"true == false", false},
@@ -105,6 +372,174 @@ func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	return true
 }
 
+func TestInputBuiltin(t *testing.T) {
+	var out bytes.Buffer
+	program := parser.New(lexer.New(`
+let name = input("name? ");
+let second = input();
+let third = input();
+[name, second, third, input()]
+`)).ParseProgram()
+	env := object.NewEnvironment()
+	SetOutput(env, &out)
+	SetInput(env, strings.NewReader("ada\r\nlovelace\nno newline"))
+
+	evaluated := Eval(program, env)
+	if evaluated.Inspect() != "[ada, lovelace, no newline, null]" {
+		t.Errorf("wrong lines read. got=%s", evaluated.Inspect())
+	}
+	if out.String() != "name? " {
+		t.Errorf("wrong prompt printed. got=%q", out.String())
+	}
+
+	errObj, ok := testEval(`input("name? ")`).(*object.Error)
+	if !ok || errObj.Message != "input() is not available in API mode" {
+		t.Errorf("input without a reader did not fail. got=%+v", errObj)
+	}
+
+	env = object.NewEnvironment()
+	SetInput(env, strings.NewReader(""))
+	errObj, ok = Eval(parser.New(lexer.New(`input("a", "b")`)).ParseProgram(), env).(*object.Error)
+	if !ok || errObj.Message != "wrong number of arguments. got=2, want=0 or 1" {
+		t.Errorf("wrong error for two prompts. got=%+v", errObj)
+	}
+}
+
+func TestStructuralEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] != [1, 2]", false},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{"[] == []", true},
+		{"[[1, [2]], 3] == [[1, [2]], 3]", true},
+		{"[[1, [2]], 3] == [[1, [3]], 3]", false},
+		{"[1] == [1.0]", true},
+		{`["a", true, null] == ["a", true, null]`, true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": 1} == {"a": 1, "b": 2}`, false},
+		{"{} == {}", true},
+		{"(1, [2]) == (1, [2])", true},
+		{"(1, 2) == (1, 3)", false},
+		{"set(1, 2) == set(2, 1)", true},
+		{"set(1, 2) == set(1, 3)", false},
+		{"[1, 2] == (1, 2)", false},
+		{`[1] == "[1]"`, false},
+		{"[1] == 1", false},
+		{`{} == []`, false},
+		{"null == []", false},
+		{"let f = fn() {}; f == f", true},
+		{"fn() {} == fn() {}", false},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b", true},
+		{"let a = [1]; a[0] = a; a == a", true},
+		{"let a = [1, 2]; a[0] = a; let b = [1, 3]; b[0] = b; a == b", false},
+		{`let h = {}; h["self"] = h; let g = {}; g["self"] = g; h == g`, true},
+		{`match ([1, 2]) { [1, 2] => true, _ => false }`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if !testBooleanObject(t, evaluated, tt.expected) {
+			t.Errorf("wrong result for %s", tt.input)
+		}
+	}
+}
+
+func TestInOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"3 in [1, 2, 3]", true},
+		{"4 in [1, 2, 3]", false},
+		{"[2] in [[1], [2]]", true},
+		{"1.0 in [1]", true},
+		{"2 in (1, 2)", true},
+		{`"k" in {"k": 1}`, true},
+		{`"v" in {"k": "v"}`, false},
+		{"2 in set(1, 2)", true},
+		{"3 in set(1, 2)", false},
+		{`"ell" in "hello"`, true},
+		{`"" in "hello"`, true},
+		{`"Hell" in "hello"`, false},
+		{"!(3 in [1, 2])", true},
+		{"1 + 1 in [2]", true},
+		{`1 in "1"`, "type mismatch: INTEGER in STRING"},
+		{`[] in {}`, "unusable as hash key: ARRAY"},
+		{`[] in set()`, "unusable as set element: ARRAY"},
+		{"1 in 1", "unknown operator: INTEGER in INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			if !testBooleanObject(t, evaluated, expected) {
+				t.Errorf("wrong result for %s", tt.input)
+			}
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("no error object returned for %s. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestArrayConcatenation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2] + [3]", "[1, 2, 3]"},
+		{"[] + [1]", "[1]"},
+		{"[1] + []", "[1]"},
+		{"[] + []", "[]"},
+		{"[1] + [2] + [3, 4]", "[1, 2, 3, 4]"},
+		{`[[1]] + ["a", null]`, "[[1], a, null]"},
+		{"let a = [1, 2]; let b = [3]; let c = a + b; c[0] = 9; c[2] = 8; [a, b, c]", "[[1, 2], [3], [9, 2, 8]]"},
+		{"let a = [1]; let b = a + []; b[0] = 5; a", "[1]"},
+		{"let a = [1]; a += [2]; a", "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if _, ok := evaluated.(*object.Array); !ok {
+			t.Errorf("object is not Array for %s. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. got=%s, want=%s", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1] + 2", "type mismatch: ARRAY + INTEGER"},
+		{`"a" + [1]`, "type mismatch: STRING + ARRAY"},
+		{"[1] + (2,)", "type mismatch: ARRAY + TUPLE"},
+		{"[1] - [1]", "unknown operator: ARRAY - ARRAY"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("wrong error for %s. expected=%q, got=%+v", tt.input, tt.expected, errObj)
+		}
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -124,6 +559,72 @@ func TestBangOperator(t *testing.T) {
 	}
 }
 
+func TestNullLiteral(t *testing.T) {
+	testNullObject(t, testEval("null"))
+	testNullObject(t, testEval("let x = null; x"))
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"null == null", true},
+		{"null != null", false},
+		{"let x = null; x == null", true},
+		{"let x = 5; x == null", false},
+		{"let x = 5; x != null", true},
+		{"if (false) { 1 } == null", true},
+		{"[1, 2][5] == null", true},
+		{`{"a": 1}["b"] != null`, false},
+		{"!null", true},
+		{"if (null) { true } else { false }", false},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestNullCoalescing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"null ?? 5", 5},
+		{"3 ?? 5", 3},
+		{"0 ?? 5", 0},
+		{`"" ?? "default"`, ""},
+		{"false ?? true", false},
+		{"null ?? null", nil},
+		{"null ?? null ?? 7", 7},
+		{`let config = {"port": 8080}; config["host"] ?? "localhost"`, "localhost"},
+		{`let config = {"port": 8080}; config["port"] ?? 80`, 8080},
+		{"[1, 2][9] ?? -1", -1},
+		{"let x = 1 ?? undefinedName; x", 1},
+		{"let calls = 0; let f = fn() { calls = calls + 1; 9 }; 2 ?? f(); calls", 0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+
+	evaluated := testEval("null ?? undefinedName")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok || errObj.Message != "identifier not found: undefinedName" {
+		t.Errorf("right side of ?? was not evaluated for null. got=%T (%+v)",
+			evaluated, evaluated)
+	}
+}
+
 func TestIfElseExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -136,6 +637,12 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if (1 > 2) { 10 }", nil},
 		{"if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (false) { 10 } else if (true) { 20 } else { 30 }", 20},
+		{"if (false) { 10 } else if (false) { 20 } else { 30 }", 30},
+		{"if (false) { 10 } else if (false) { 20 }", nil},
+		{"if (true) { 10 } else if (true) { 20 }", 10},
+		{"let x = 3; if (x == 1) { 10 } else if (x == 2) { 20 } else if (x == 3) { 30 } else { 40 }", 30},
+		{"let f = fn(x) { if (x < 0) { return -1; } else if (x > 0) { return 1; } 0 }; f(5) + f(-5) + f(0)", 0},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -147,23 +654,178 @@ func TestIfElseExpressions(t *testing.T) {
 		}
 	}
 }
-func testNullObject(t *testing.T, obj object.Object) bool {
-	if obj != NULL {
-		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
-		return false
+func TestTernaryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true ? 10 : 20", 10},
+		{"false ? 10 : 20", 20},
+		{"1 < 2 ? 10 : 20", 10},
+		{"let x = -5; let abs = x >= 0 ? x : -x; abs;", 5},
+		{"if (false) { 1 } ? 10 : 20", 20},
+		{"0 ? 10 : 20", 10},
+		{"let x = 2; x == 1 ? 10 : x == 2 ? 20 : 30", 20},
+		{"let x = 3; x == 1 ? 10 : x == 2 ? 20 : 30", 30},
+		{"true ? false ? 1 : 2 : 3", 2},
+		{"let n = 0; let bump = fn() { n = n + 1; n }; true ? 5 : bump(); false ? bump() : 6; n", 0},
+		{"true ? 1 : missing", 1},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
 	}
-	return true
 }
 
-func TestReturnStatements(t *testing.T) {
+func TestMatchExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected int64
+		expected interface{}
 	}{
-		{"return 10;", 10},
-		{"return 10; 9;", 10},
-		{"return 2 * 5; 9;", 10},
-		{"9; return 2 * 5; 9;", 10},
+		{`let x = 1; match (x) { 1 => "one", 2 => "two", _ => "many" }`, "one"},
+		{`let x = 2; match (x) { 1 => "one", 2 => "two", _ => "many" }`, "two"},
+		{`let x = 7; match (x) { 1 => "one", 2 => "two", _ => "many" }`, "many"},
+		{`let x = 7; match (x) { 1 => "one", 2 => "two" }`, nil},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{`match (2.0) { 2 => "int two", _ => "other" }`, "int two"},
+		{`match (null) { null => "nothing", _ => "something" }`, "nothing"},
+		{`match (true) { false => 0, true => 1 }`, 1},
+		{`let limit = 3; match (1 + 2) { limit => "at limit", _ => "under" }`, "at limit"},
+		{`match (1) { "1" => "string", 1 => "integer" }`, "integer"},
+		{`match (5) { }`, nil},
+		{`let calls = 0; let f = fn() { calls = calls + 1; 1 }; match (1) { f() => "a", f() => "b" }; calls`, 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`try { 1 } catch (e) { 2 }`, 1},
+		{`try { missing } catch (e) { 2 }`, 2},
+		{`try { throw "boom"; 1 } catch (e) { 2 }`, 2},
+		{`let runs = 0; try { 1 } finally { runs = runs + 1 }; runs`, 1},
+		{`let runs = 0; try { missing } catch (e) { runs = runs + 1 } finally { runs = runs * 10 }; runs`, 10},
+		{`let runs = 0; let f = fn() { try { return 1 } finally { runs = runs + 1 }; 2 }; f() + runs`, 2},
+		{`let f = fn() { try { missing } catch (e) { return 3 }; 4 }; f()`, 3},
+		{`let f = fn() { try { return 1 } finally { return 5 } }; f()`, 5},
+		{`let e = 1; try { missing } catch (e) { 0 }; e`, 1},
+		{`let n = 0; while (n < 10) { try { n = n + 1; if (n == 3) { break } } catch (e) { 0 } }; n`, 3},
+		{`try { try { missing } finally { 0 } } catch (e) { 7 }`, 7},
+		{`try { missing } catch (e) { e }`, "identifier not found: missing"},
+		{`try { throw "boom" } catch (e) { "caught " + e }`, "caught boom"},
+		{`try { len(1) } catch (e) { e }`, "argument to `len` not supported, got INTEGER"},
+		{`let f = fn(x) { 10 / x + missing }; try { f(2) } catch (e) { e }`, "identifier not found: missing"},
+		{`try { try { throw "inner" } catch (e) { throw e } } catch (e) { e }`, "inner"},
+		{`let parse = fn(s) { try { yamlParse(s) } catch (e) { null } }; parse("a: [") == null`, true},
+		{`try { 1 } catch (e) { e } == 1`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestTryDoesNotCatchLimits(t *testing.T) {
+	defer func(limit int) { maxLoopIterations = limit }(maxLoopIterations)
+	maxLoopIterations = 1000
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`try { while (true) {} } catch (e) { 1 }`, "loop exceeded the maximum of 1000 iterations"},
+		{`let f = fn() { f() }; try { f() } catch (e) { 1 }`, "maximum call stack size exceeded"},
+		{`let f = fn() { try { f() } catch (e) { 1 } }; f()`, "maximum call stack size exceeded"},
+		{`try { for (;;) {} } finally { return 1 }`, "loop exceeded the maximum of 1000 iterations"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %s. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestThrowAndCatchErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`throw "boom"`, "boom"},
+		{`throw 42`, "42"},
+		{`let f = fn(x) { if (x < 0) { throw "negative" } x }; f(-1)`, "negative"},
+		{`try { missing } catch (e) { throw e }`, "identifier not found: missing"},
+		{`try { throw "first" } catch (e) { throw "second" }`, "second"},
+		{`try { 1 } finally { throw "from finally" }`, "from finally"},
+		{`try { missing } finally { 0 }`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)",
+				tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
 		{
 			`
 			if (10 > 1) {
@@ -191,6 +853,9 @@ func TestErrorHandling(t *testing.T) {
 			"5 + true;",
 			"type mismatch: INTEGER + BOOLEAN",
 		},
+		{"10 / 0", "division by zero"},
+		{"let d = 5 - 5; 10 / d", "division by zero"},
+		{"let x = 5; x /= 0; x", "division by zero"},
 		{
 			"5 + true; 5;",
 			"type mismatch: INTEGER + BOOLEAN",
@@ -230,6 +895,52 @@ func TestErrorHandling(t *testing.T) {
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
 		},
+		{
+			`1 <= "1"`,
+			"type mismatch: INTEGER <= STRING",
+		},
+		{
+			"true >= false",
+			"unknown operator: BOOLEAN >= BOOLEAN",
+		},
+		{
+			"1 << -1",
+			"invalid shift count: -1",
+		},
+		{
+			"1 >> 64",
+			"invalid shift count: 64",
+		},
+		{
+			"~true",
+			"unknown operator: ~BOOLEAN",
+		},
+		{
+			"true & false",
+			"unknown operator: BOOLEAN & BOOLEAN",
+		},
+		{
+			"true xor false",
+			"unknown operator: BOOLEAN xor BOOLEAN",
+		},
+		{
+			`"a" | "b"`,
+			"unknown operator: STRING | STRING",
+		},
+		{
+			`1 << "2"`,
+			"type mismatch: INTEGER << STRING",
+		},
+		{
+			`~"a"`,
+			"unknown operator: ~STRING",
+		},
+		{
+			"[1] & [1]",
+			"unknown operator: ARRAY & ARRAY",
+		},
+		{"let f = fn(a, b) { a }; f(1);", "wrong number of arguments. got=1, want=2"},
+		{"let f = fn() { 1 }; f(1, 2);", "wrong number of arguments. got=2, want=0"},
 	}
 
 	for _, tt := range tests {
@@ -258,6 +969,7 @@ func TestLetStatements(t *testing.T) {
 		{"let a = 5 * 5; a;", 25},
 		{"let a = 5; let b = a; b;", 5},
 		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+		{"let café = 2; let 変数 = 3; café * 変数;", 6},
 	}
 
 	for _, tt := range tests {
@@ -265,155 +977,1263 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
-func TestFunctionObject(t *testing.T) {
-	input := "fn(x) { x + 2; };"
-
-	evaluated := testEval(input)
-	fn, ok := evaluated.(*object.Function)
-	if !ok {
-		t.Fatalf("object is not Function, got=%T (%+v)", evaluated, evaluated)
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"const PI = 3; PI;", 3},
+		{"const PI = 3; let area = fn(r) { PI * r * r }; area(2);", 12},
+		{"const PI = 3; let f = fn() { let PI = 4; PI }; f();", 4},
+		{"let x = 3; let f = fn() { const x = 4; x }; f() + x;", 7},
+		{"const PI = 3; let f = fn() { let PI = 4; const g = fn() { const PI = 5; PI }; g() }; f();", 5},
+		{"const PI = 3; let f = fn(PI) { PI = PI + 1; PI }; f(1);", 2},
 	}
 
-	if len(fn.Parameters) != 1 {
-		t.Fatalf("function has wrong parameters. Parameters:%+v", fn.Parameters)
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
+}
 
-	if fn.Parameters[0].String() != "x" {
-		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+func TestConstReassignmentErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"const PI = 3; PI = 4;", "cannot reassign constant 'PI'"},
+		{"const PI = 3; let PI = 4;", "cannot reassign constant 'PI'"},
+		{"const PI = 3; const PI = 4;", "cannot reassign constant 'PI'"},
+		{"const PI = 3; PI += 1;", "cannot reassign constant 'PI'"},
+		{"const PI = 3; PI++;", "cannot reassign constant 'PI'"},
+		{"const PI = 3; let f = fn() { PI = 4; }; f();", "cannot reassign constant 'PI'"},
+		{"const PI = 3; let f = fn() { const PI = 4; PI }; f();", "cannot shadow constant 'PI'"},
+		{"const PI = 3; let f = fn() { fn() { const PI = 4; } }; f()();", "cannot shadow constant 'PI'"},
 	}
 
-	expectedBody := "(x + 2)"
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
 
-	if fn.Body.String() != expectedBody {
-		t.Fatalf("body is not %q. got=%q", expectedBody, fn.Body.String())
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
 	}
-
 }
 
-func TestFunctionApplication(t *testing.T) {
+func TestBlockScopes(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected int64
 	}{
-		{"let identity = fn(x) { x; }; identity(5);", 5},
-		{"let identity = fn(x) { return x; }; identity(5);", 5},
-		{"let double = fn(x) { x * 2; }; double(5);", 10},
-		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
-		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
-		{"fn(x) { x; }(5)", 5},
+		{"let x = 1; if (true) { let x = 2; }; x", 1},
+		{"let x = 1; if (false) { 0 } else { let x = 2; }; x", 1},
+		{"let x = 1; if (true) { x = 2; }; x", 2},
+		{"let x = 1; if (true) { let x = 2; if (true) { let x = 3; } x } * 10 + x", 21},
+		{"let x = 1; for (let i = 0; i < 3; i++) { let x = i; }; x", 1},
+		{"let x = 1; let i = 0; while (i < 3) { let x = 10; i++; }; x", 1},
+		{"let x = 1; do { let x = 5; } while (false); x", 1},
+		{"let f = fn(a) { let a = a * 2; a }; f(4)", 8},
 	}
 
 	for _, tt := range tests {
 		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
-}
-
-func TestClosures(t *testing.T) {
-	input := `
-let newAdder = fn(x) {
-fn(y) { x + y };
-};
-let addTwo = newAdder(2);
-addTwo(2);`
 
-	testIntegerObject(t, testEval(input), 4)
+	evaluated := testEval("if (true) { let inner = 1; }; inner")
+	errObj, ok := evaluated.(*object.Error)
+	if !ok || errObj.Message != "identifier not found: inner" {
+		t.Errorf("binding leaked out of an if block. got=%T (%+v)", evaluated, evaluated)
+	}
 }
 
-func TestStringLiteral(t *testing.T) {
-	input := `"Hello World!"`
-	evaluated := testEval(input)
-
-	str, ok := evaluated.(*object.String)
-	if !ok {
-		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+func TestRedeclarationErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"let x = 1; let x = 2;", "identifier 'x' has already been declared"},
+		{"let x = 1; const x = 2;", "identifier 'x' has already been declared"},
+		{"let f = fn(a, a) { a }; f(1, 2);", "identifier 'a' has already been declared"},
+		{"let x = 1; let f = fn() { let x = 2; let x = 3; }; f();", "identifier 'x' has already been declared"},
 	}
 
-	if str.Value != "Hello World!" {
-		t.Errorf("String has wrong value. got=%q", str.Value)
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
 	}
 }
 
-func TestStringConcatenation(t *testing.T) {
-	input := `"Hello" + " " + "World!"`
-	evaluated := testEval(input)
-	str, ok := evaluated.(*object.String)
-	if !ok {
-
-		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+func TestAssignmentExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x = 2; x;", 2},
+		{"let x = 1; x = x + 5;", 6},
+		{"let x = 1; let y = 2; x = y = 3; x + y;", 6},
+		{"let x = 1; let set = fn(v) { x = v; }; set(7); x;", 7},
+		{"let x = 1; let f = fn() { let x = 2; x = 3; x; }; f() * 10 + x;", 31},
+		{`
+let makeCounter = fn() {
+	let count = 0;
+	fn() {
+		count = count + 1;
+		count;
+	};
+};
+let counter = makeCounter();
+counter();
+counter();
+counter();`, 3},
 	}
 
-	if str.Value != "Hello World!" {
-		t.Errorf("String has wrong value. got=%q", str.Value)
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
-}
 
-func TestStringComparison(t *testing.T) {
-	input := `"Hello" == "Hello"`
-	evaluated := testEval(input)
-	res, ok := evaluated.(*object.Boolean)
+	errObj, ok := testEval("y = 5;").(*object.Error)
 	if !ok {
-		t.Fatalf("object is not Boolean. got=%T (%+v)", evaluated, evaluated)
+		t.Fatalf("assignment to undeclared identifier did not error")
 	}
-
-	if res.Value != true {
-		t.Errorf("Result has wrong value. got=%v", res.Value)
+	expected := "cannot assign to undeclared identifier y, use let to declare it first"
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
 	}
 }
 
-func TestBuiltinFunctions(t *testing.T) {
+func TestCompoundAssignment(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected any
+		expected int64
 	}{
-		{`len("")`, 0},
-		{`len("four")`, 4},
-		{`len("hello world")`, 11},
-		{`len(1)`, "argument to `len` not supported, got INTEGER"},
-		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
+		{"let a = 5; a += 3; a;", 8},
+		{"let a = 5; a -= 3; a;", 2},
+		{"let a = 5; a *= 3; a;", 15},
+		{"let a = 15; a /= 3; a;", 5},
+		{"let a = 5; a += 3;", 8},
+		{"let a = 1; let b = 2; a += b *= 10; a;", 21},
 	}
+
 	for _, tt := range tests {
-		evaluated := testEval(tt.input)
-		switch expected := tt.expected.(type) {
-		case int:
-			testIntegerObject(t, evaluated, int64(expected))
-		case string:
-			errObj, ok := evaluated.(*object.Error)
-			if !ok {
-				t.Errorf("object is not Error. got=%T (%+v)",
-					evaluated, evaluated)
-				continue
-			}
-			if errObj.Message != expected {
-				t.Errorf("wrong error message. expected=%q, got=%q",
-					expected, errObj.Message)
-			}
-		}
+		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
-}
 
-func TestArrayLiterals(t *testing.T) {
-	input := "[1, 2 * 2, 3 + 3]"
-	evaluated := testEval(input)
-	result, ok := evaluated.(*object.Array)
+	str := testEval(`let s = "foo"; s += "bar"; s;`)
+	if result, ok := str.(*object.String); !ok || result.Value != "foobar" {
+		t.Errorf("string += wrong. got=%T (%+v)", str, str)
+	}
+
+	errObj, ok := testEval("missing += 1").(*object.Error)
 	if !ok {
-		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+		t.Fatalf("compound assignment on undeclared identifier did not error")
 	}
-	if len(result.Elements) != 3 {
-		t.Fatalf("array has wrong num of elements. got=%d",
-			len(result.Elements))
+	if errObj.Message != "identifier not found: missing" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
 	}
-	testIntegerObject(t, result.Elements[0], 1)
-	testIntegerObject(t, result.Elements[1], 4)
-	testIntegerObject(t, result.Elements[2], 6)
 }
 
-func TestArrayIndexExpressions(t *testing.T) {
+func TestIncrementDecrement(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected interface{}
+		expected int64
 	}{
-		{
-			"[1, 2, 3][0]",
+		{"let a = 5; ++a;", 6},
+		{"let a = 5; a++;", 5},
+		{"let a = 5; a++; a;", 6},
+		{"let a = 5; --a;", 4},
+		{"let a = 5; a--;", 5},
+		{"let a = 5; a--; a;", 4},
+		{"let a = 1; let b = a++ + a; b;", 3},
+		{"let a = 1; let b = ++a + a; b;", 4},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCompoundAssignmentUpdatesEnclosingScope(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let total = 0; let add = fn(x) { total += x; }; add(2); add(3); total;", 5},
+		{"let n = 10; let halve = fn() { n /= 2; }; halve(); halve(); n;", 2},
+		{"let count = 0; let tick = fn() { count++; }; tick(); tick(); tick(); count;", 3},
+		{"let count = 0; let tick = fn() { --count; }; tick(); count;", -1},
+		{`
+let makeCounter = fn() {
+	let count = 0;
+	fn() { count += 1; };
+};
+let counter = makeCounter();
+counter();
+counter();`, 2},
+		{"let x = 1; let f = fn(x) { x += 10; x; }; f(5) + x;", 16},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIncrementDecrementErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`let s = "a"; s++;`, "unknown operator: STRING++"},
+		{`let b = true; --b;`, "unknown operator: --BOOLEAN"},
+		{"5++", "invalid operand for ++: 5"},
+		{"--5", "invalid operand for --: 5"},
+		{"missing++", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for (let i = 0; i < 5; i++) { sum += i; }; sum;", 10},
+		{"let sum = 0; for (let i = 0; i < 10; i++) { if (i == 3) { break; } sum += i; }; sum;", 3},
+		{"let sum = 0; for (let i = 0; i < 5; i++) { if (i == 2) { continue; } sum += i; }; sum;", 8},
+		{"let n = 0; for (;;) { n++; if (n == 4) { break; } }; n;", 4},
+		{"let n = 0; for (; n < 3;) { n++; }; n;", 3},
+		{"let f = fn() { for (let i = 0; i < 10; i++) { if (i == 7) { return i; } } }; f();", 7},
+		{"let count = 0; for (let i = 0; i < 3; i++) { for (let j = 0; j < 3; j++) { if (j == 1) { break; } count++; } }; count;", 3},
+		{"for (let i = 0; i < 1; i++) { let inner = i; }", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestForStatementScoping(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"for (let i = 0; i < 2; i++) {}; i;", "identifier not found: i"},
+		{"for (let i = 0; i < 2; i++) { let inner = i; }; inner;", "identifier not found: inner"},
+		{"for (let i = 0; i < missing; i++) {}", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestWhileStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while (i < 5) { i++; }; i;", 5},
+		{"let i = 0; let sum = 0; while (true) { i++; if (i > 4) { break; } sum += i; }; sum;", 10},
+		{"let i = 0; let sum = 0; while (i < 5) { i++; if (i == 3) { continue; } sum += i; }; sum;", 12},
+		{"let f = fn() { let i = 0; while (true) { i++; if (i == 6) { return i; } } }; f();", 6},
+		{"while (false) { 1 }", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDoWhileStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; do { i++; } while (i < 5); i;", 5},
+		{"let i = 10; do { i++; } while (i < 5); i;", 11},
+		{"let i = 0; do { i++; if (i == 3) { break; } } while (true); i;", 3},
+		{"let i = 0; let sum = 0; do { i++; if (i == 2) { continue; } sum += i; } while (i < 4); sum;", 8},
+		{"let f = fn() { do { return 9; } while (true); }; f();", 9},
+		{"do { 1 } while (false);", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"break;", "break outside of a loop"},
+		{"continue;", "continue outside of a loop"},
+		{"if (true) { break; }", "break outside of a loop"},
+		{"let f = fn() { continue; }; f();", "continue outside of a loop"},
+		{"for (let i = 0; i < 3; i++) { let f = fn() { break; }; f(); }", "break outside of a loop"},
+		{"let i = 0; while (i < 3) { i++; fn() { if (true) { continue; } }(); }", "continue outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)",
+				tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestLoopIterationLimit(t *testing.T) {
+	defer func(limit int) { maxLoopIterations = limit }(maxLoopIterations)
+	maxLoopIterations = 100
+
+	tests := []string{
+		"while (true) {}",
+		"for (;;) {}",
+		"do {} while (true);",
+		"let i = 0; while (true) { i++; continue; }",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)",
+				input, evaluated, evaluated)
+			continue
+		}
+
+		expected := "loop exceeded the maximum of 100 iterations"
+		if errObj.Message != expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				expected, errObj.Message)
+		}
+	}
+
+	testIntegerObject(t, testEval("let i = 0; while (i < 100) { i++; }; i;"), 100)
+}
+
+func TestFunctionObject(t *testing.T) {
+	input := "fn(x) { x + 2; };"
+
+	evaluated := testEval(input)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function, got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("function has wrong parameters. Parameters:%+v", fn.Parameters)
+	}
+
+	if fn.Parameters[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	}
+
+	expectedBody := "(x + 2)"
+
+	if fn.Body.String() != expectedBody {
+		t.Fatalf("body is not %q. got=%q", expectedBody, fn.Body.String())
+	}
+
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestVariadicFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(a, b, ...rest) { rest }; f(1, 2, 3, 4)", "[3, 4]"},
+		{"let f = fn(a, b, ...rest) { rest }; f(1, 2)", "[]"},
+		{"let f = fn(...all) { all }; f()", "[]"},
+		{"let f = fn(a, ...rest) { [a, len(rest)] }; f(1, 2, 3)", "[1, 2]"},
+		{"let f = fn(a, b, c) { [c, b, a] }; f(...[1, 2, 3])", "[3, 2, 1]"},
+		{"let f = fn(a, b, c) { [a, b, c] }; let xs = [2, 3]; f(1, ...xs)", "[1, 2, 3]"},
+		{"let f = fn(...rest) { rest }; f(0, ...[1, 2], 3, ...[])", "[0, 1, 2, 3]"},
+		{"push(...[[1], 2])", "[1, 2]"},
+		{"let rest = [2, 3]; [1, ...rest, 9]", "[1, 2, 3, 9]"},
+		{"[...[], ...[1], ...[[2]]]", "[1, [2]]"},
+		{"let xs = [1, 2]; let ys = [...xs]; ys[0] = 5; xs", "[1, 2]"},
+		{"fn count(...nums) { len(nums) }; [count(), count(1), count(1, 2, 3)]", "[0, 1, 3]"},
+		{"fn f(a, ...rest) { [a, rest] }; [f(1), f(1, 2, 3)]", "[[1, []], [1, [2, 3]]]"},
+		{`
+let sum = fn(...nums) {
+	let total = 0;
+	for (let i = 0; i < len(nums); i++) { total += nums[i]; }
+	total;
+};
+let forward = fn(...args) { sum(...args) };
+[forward(1, 2, 3, 4)]`, "[10]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. want=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(a, b, ...rest) { rest }; f(1)", "wrong number of arguments. got=1, want at least 2"},
+		{"let f = fn(a) { a }; f(...5)", "spread argument must be ARRAY, got INTEGER"},
+		{`[1, ..."ab"]`, "spread argument must be ARRAY, got STRING"},
+		{"let f = fn(a) { a }; f(...[1, 2])", "wrong number of arguments. got=2, want=1"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)",
+				tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestDefaultParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let f = fn(x, y = 10, z = "hello") { [x, y, z] }; f(1)`, "[1, 10, hello]"},
+		{`let f = fn(x, y = 10, z = "hello") { [x, y, z] }; f(1, 2)`, "[1, 2, hello]"},
+		{`let f = fn(x, y = 10, z = "hello") { [x, y, z] }; f(1, 2, 3)`, "[1, 2, 3]"},
+		{`let f = fn(x, y = 10) { [x, y] }; f(1, null)`, "[1, 10]"},
+		{`let f = fn(x, y = 10) { [x, y] }; f(null, null)`, "[null, 10]"},
+		{`let f = fn(x = 1, ...rest) { [x, rest] }; f()`, "[1, []]"},
+		{`let f = fn(x = 1, ...rest) { [x, rest] }; f(5, 6, 7)`, "[5, [6, 7]]"},
+		{`let base = 100; let f = fn(x = base + 1) { x }; let g = fn() { let base = 0; f() }; [g()]`, "[101]"},
+		{`let n = 1; let f = fn(x = n) { x }; n = 2; [f()]`, "[2]"},
+		{"let calls = 0; let next = fn() { calls = calls + 1; calls }; let f = fn(x = next()) { x }; f(); f(9); [f(), calls]", "[2, 2]"},
+		{`fn greet(name, greeting = "hello") { greeting + " " + name }; [greet("ada"), greet("ada", "hi")]`, "[hello ada, hi ada]"},
+		{`fn fact(n) { if (n < 2) { 1 } else { n * fact(n - 1) } }; [fact(5)]`, "[120]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. want=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn(x, y = 1) { x }; f()", "wrong number of arguments. got=0, want at least 1"},
+		{"let f = fn(x, y = 1) { x }; f(1, 2, 3)", "wrong number of arguments. got=3, want at most 2"},
+		{"let f = fn(x = missing) { x }; f()", "identifier not found: missing"},
+		{"let f = fn(y, x = y) { x }; f(1)", "identifier not found: y"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)",
+				tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestClosures(t *testing.T) {
+	input := `
+let newAdder = fn(x) {
+fn(y) { x + y };
+};
+let addTwo = newAdder(2);
+addTwo(2);`
+
+	testIntegerObject(t, testEval(input), 4)
+}
+
+// Closures capture their enclosing environment by reference: they see
+// later assignments to captured variables, and closures created in a loop
+// body share the single loop variable declared by the for statement's
+// init clause. Copying it into a let inside the body captures the value
+// of each iteration instead.
+func TestClosuresCaptureByReference(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`
+let makeCounter = fn() {
+	let count = 0;
+	fn() { count = count + 1; count };
+};
+let counter = makeCounter();
+counter();
+counter();
+counter();`, 3},
+		{`
+let makeCounter = fn() {
+	let count = 0;
+	fn() { count = count + 1; count };
+};
+let a = makeCounter();
+let b = makeCounter();
+a();
+a();
+b() * 10 + a();`, 13},
+		{`
+let makePair = fn() {
+	let n = 0;
+	[fn() { n = n + 1; }, fn() { n }];
+};
+let pair = makePair();
+pair[0]();
+pair[0]();
+pair[1]();`, 2},
+		{"let x = 1; let f = fn() { x }; x = 5; f();", 5},
+		{"let x = 1; let set = fn() { x = 10; }; set(); x;", 10},
+		{`
+let fns = [];
+for (let i = 0; i < 3; i++) {
+	fns = push(fns, fn() { i });
+}
+fns[0]() + fns[2]();`, 6},
+		{`
+let fns = [];
+for (let i = 0; i < 3; i++) {
+	let captured = i;
+	fns = push(fns, fn() { captured });
+}
+fns[0]() * 10 + fns[2]();`, 2},
+		{`
+let fns = [];
+let i = 0;
+while (i < 3) {
+	let captured = i;
+	fns = push(fns, fn() { captured * 100 + i });
+	i++;
+}
+fns[1]();`, 103},
+		{`
+let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } };
+if (isEven(10)) { isOdd(7) } else { false };`, true},
+		{`
+let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+fib(15);`, 610},
+		{`
+let outer = fn() {
+	let x = 1;
+	let inner = fn() { x = x + 1; };
+	inner();
+	inner();
+	x;
+};
+outer() + outer();`, 6},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestStringLiteral(t *testing.T) {
+	input := `"Hello World!"`
+	evaluated := testEval(input)
+
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = 2; let b = 3; "sum is ${a + b}"`, "sum is 5"},
+		{`let name = "banana"; "${name}s"`, "bananas"},
+		{`"${[1, 2]} and ${true} and ${1.5}"`, "[1, 2] and true and 1.5"},
+		{`let h = {"k": "v"}; "value: ${h["k"]}"`, "value: v"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`"price: \${amount}"`, "price: ${amount}"},
+		{`let who = 'world'; 'hello ${who}'`, "hello world"},
+		{`let f = fn(x) { x * 2 }; "${f(21)}"`, "42"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+
+	evaluated := testEval(`"${missing}"`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok || errObj.Message != "identifier not found: missing" {
+		t.Errorf("undefined identifier did not return the expected error. got=%T (%+v)",
+			evaluated, evaluated)
+	}
+}
+
+func TestStringConcatenation(t *testing.T) {
+	input := `"Hello" + " " + "World!"`
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	input := `"Hello" == "Hello"`
+	evaluated := testEval(input)
+	res, ok := evaluated.(*object.Boolean)
+	if !ok {
+		t.Fatalf("object is not Boolean. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if res.Value != true {
+		t.Errorf("Result has wrong value. got=%v", res.Value)
+	}
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("hello world")`, 11},
+		{`len("héllo")`, 5},
+		{`len("🍌👋")`, 2},
+		{`len("日本語")`, 3},
+		{`len([1, 2, 3])`, 3},
+		{`len([])`, 0},
+		{`len({})`, 0},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`let h = {"a": 1}; h["b"] = 2; h["a"] = 3; len(h)`, 2},
+		{`len([[1, 2, 3], [], ["four"]])`, 3},
+		{`len(["hello"])`, 1},
+		{`len({"a": {"b": 1, "c": 2}, "d": [1, 2, 3]})`, 2},
+		{`len({"a": [1, 2, 3]}["a"])`, 3},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len(fn() {})`, "argument to `len` not supported, got FUNCTION"},
+		{`len()`, "wrong number of arguments. got=0, want=1"},
+		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
+	}
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)",
+					evaluated, evaluated)
+				continue
+			}
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q",
+					expected, errObj.Message)
+			}
+		}
+	}
+}
+
+func TestRangeBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"range(5)", "[0, 1, 2, 3, 4]"},
+		{"range(0)", "[]"},
+		{"range(2, 5)", "[2, 3, 4]"},
+		{"range(3, 3)", "[]"},
+		{"range(0, 10, 3)", "[0, 3, 6, 9]"},
+		{"range(5, 0, -1)", "[5, 4, 3, 2, 1]"},
+		{"range(10, 0, -2)", "[10, 8, 6, 4, 2]"},
+		{"len(range(1000000))", "1000000"},
+		{"range(-2, -8, -3)", "[-2, -5]"},
+		{"range(9223372036854775805, 9223372036854775807)", "[9223372036854775805, 9223372036854775806]"},
+		{"range(0, 9223372036854775807, 4611686018427387904)", "[0, 4611686018427387904]"},
+		{"map(range(1, 4), fn(x) { x * x })", "[1, 4, 9]"},
+		{"filter(range(10), fn(x) { x / 3 * 3 == x })", "[0, 3, 6, 9]"},
+		{"let sum = 0; let r = range(1, 5); for (let i = 0; i < len(r); i++) { sum += r[i] }; sum", "10"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. expected=%s, got=%+v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"range()", "wrong number of arguments. got=0, want=1 to 3"},
+		{"range(1, 2, 3, 4)", "wrong number of arguments. got=4, want=1 to 3"},
+		{`range("5")`, "arguments to `range` must be INTEGER, got STRING"},
+		{"range(0, 1.5)", "arguments to `range` must be INTEGER, got FLOAT"},
+		{"range(0, 5, 0)", "step argument to `range` must not be zero"},
+		{"range(0, 5, -1)", "range(0, 5, -1) never reaches its stop"},
+		{"range(5, 0)", "range(5, 0, 1) never reaches its stop"},
+		{"range(-1)", "range(0, -1, 1) never reaches its stop"},
+		{"range(9223372036854775807)", "range would exceed 1000000 elements"},
+		{"range(1000000000)", "range would exceed 1000000 elements"},
+		{"range(-1, 1000000)", "range would exceed 1000000 elements"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("wrong error for %s. expected=%q, got=%+v", tt.input, tt.expected, errObj)
+		}
+	}
+}
+
+func TestErrorBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`error("boom")`, "boom"},
+		{`error(42)`, "42"},
+		{`let sqrt = fn(x) { if (x < 0) { return error("x must be non-negative") } x }; sqrt(-1)`, "x must be non-negative"},
+		{`let f = fn() { error("stop"); 1 }; f() + 1`, "stop"},
+		{`let g = fn() { error("inner") }; let h = fn() { g() }; [h()]`, "inner"},
+		{`try { error("caught") } catch (e) { error("again: " + e) }`, "again: caught"},
+		{`error()`, "wrong number of arguments. got=0, want=1"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("wrong error for %s. expected=%q, got=%+v", tt.input, tt.expected, errObj)
+		}
+	}
+
+	testStringObject(t, testEval(`try { error("x") } catch (e) { e }`), "x")
+}
+
+func TestAssertBuiltin(t *testing.T) {
+	testNullObject(t, testEval("assert(true)"))
+	testNullObject(t, testEval(`assert(1 + 1 == 2, "math is broken")`))
+	testNullObject(t, testEval("assert(0)"))
+	testIntegerObject(t, testEval("assert(true); 5"), 5)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`assert(false, "bad")`, "bad"},
+		{"assert(false)", "assertion failed"},
+		{"assert(null)", "assertion failed"},
+		{`let f = fn(x) { assert(x > 0, "x must be positive"); x }; f(-1)`, "x must be positive"},
+		{`assert(false, "first"); assert(false, "second")`, "first"},
+		{`try { assert(false, "caught") } catch (e) { throw "rethrown " + e }`, "rethrown caught"},
+		{"assert()", "wrong number of arguments. got=0, want=1 or 2"},
+		{`assert(true, "a", "b")`, "wrong number of arguments. got=3, want=1 or 2"},
+		{"assert(true, 1)", "second argument to `assert` must be STRING, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("wrong error for %s. expected=%q, got=%+v", tt.input, tt.expected, errObj)
+		}
+	}
+}
+
+func TestFirstLastRestReverse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"first([1, 2, 3])", "1"},
+		{"first([])", "null"},
+		{"last([1, 2, 3])", "3"},
+		{"last([])", "null"},
+		{"rest([1, 2, 3])", "[2, 3]"},
+		{"rest([1])", "[]"},
+		{"rest([])", "[]"},
+		{"let a = [1, 2]; let r = rest(a); r[0] = 5; a", "[1, 2]"},
+		{"reverse([1, 2, 3])", "[3, 2, 1]"},
+		{"reverse([1])", "[1]"},
+		{"reverse([])", "[]"},
+		{"let a = [1, 2]; let r = reverse(a); [a, r]", "[[1, 2], [2, 1]]"},
+		{`reverse("abc")`, "cba"},
+		{`reverse("")`, ""},
+		{`reverse("héllo, 世界")`, "界世 ,olléh"},
+		{`
+let map = fn(arr, f) {
+	let iter = fn(arr, accumulated) {
+		if (len(arr) == 0) {
+			accumulated
+		} else {
+			iter(rest(arr), push(accumulated, f(first(arr))))
+		}
+	};
+	iter(arr, [])
+};
+map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. want=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"first()", "wrong number of arguments. got=0, want=1"},
+		{`first("abc")`, "argument to `first` must be ARRAY, got STRING"},
+		{"last([1], [2])", "wrong number of arguments. got=2, want=1"},
+		{"last(1)", "argument to `last` must be ARRAY, got INTEGER"},
+		{"rest()", "wrong number of arguments. got=0, want=1"},
+		{"rest({})", "argument to `rest` must be ARRAY, got HASH"},
+		{"reverse()", "wrong number of arguments. got=0, want=1"},
+		{"reverse(123)", "argument to `reverse` must be ARRAY or STRING, got INTEGER"},
+		{"reverse(null)", "argument to `reverse` must be ARRAY or STRING, got NULL"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestPushPopShiftUnshift(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"push([], 1)", "[1]"},
+		{"push(push([], 1), 2)", "[1, 2]"},
+		{"let a = [1]; let b = push(a, 2); [a, b]", "[[1, 2], [1, 2]]"},
+		{"let a = [1]; let b = a; push(a, 2); b", "[1, 2]"},
+		{"let a = [1]; let f = fn(arr) { push(arr, 9) }; f(a); a", "[1, 9]"},
+		{"pop([1, 2, 3])", "3"},
+		{"let a = [1, 2, 3]; let b = a; let last = pop(a); [last, a, b]", "[3, [1, 2], [1, 2]]"},
+		{"let a = [1]; pop(a); push(a, 2); a", "[2]"},
+		{"unshift([2, 3], 1)", "[1, 2, 3]"},
+		{"unshift([], 1)", "[1]"},
+		{"let a = [2]; let b = a; unshift(a, 1); b", "[1, 2]"},
+		{"shift([1, 2, 3])", "1"},
+		{"let a = [1, 2, 3]; let b = a; let first = shift(a); [first, a, b]", "[1, [2, 3], [2, 3]]"},
+		{"let a = [1, 2]; shift(a); shift(a); a", "[]"},
+		{"insert([1, 3], 1, 2)", "[1, 2, 3]"},
+		{"insert([2], 0, 1)", "[1, 2]"},
+		{"insert([1], 1, 2)", "[1, 2]"},
+		{"insert([], 0, 1)", "[1]"},
+		{"let a = [1, 3]; let b = a; insert(a, 1, 2); b", "[1, 2, 3]"},
+		{"let a = [1, 2]; let s = a[0:1]; push(s, 9); [a, s]", "[[1, 2], [1, 9]]"},
+		{"let a = [1, 2]; let b = a + []; push(b, 3); a", "[1, 2]"},
+		{"let queue = []; push(queue, 1); push(queue, 2); let x = shift(queue); [x, queue]", "[1, [2]]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. want=%s, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"push(1, 2)", "argument to `push` must be ARRAY, got INTEGER"},
+		{"pop()", "wrong number of arguments. got=0, want=1"},
+		{`pop("abc")`, "argument to `pop` must be ARRAY, got STRING"},
+		{"unshift([1])", "wrong number of arguments. got=1, want=2"},
+		{"unshift({}, 1)", "argument to `unshift` must be ARRAY, got HASH"},
+		{"shift([1], [2])", "wrong number of arguments. got=2, want=1"},
+		{"shift(null)", "argument to `shift` must be ARRAY, got NULL"},
+		{"pop([])", "cannot pop from an empty array"},
+		{"shift([])", "cannot shift from an empty array"},
+		{"insert([1], 0)", "wrong number of arguments. got=2, want=3"},
+		{"insert((1, 2), 0, 1)", "first argument to `insert` must be ARRAY, got TUPLE"},
+		{`insert([1], "0", 1)`, "second argument to `insert` must be INTEGER, got STRING"},
+		{"insert([1, 2], 3, 0)", "index out of range: 3 (array length 2)"},
+		{"insert([1, 2], -1, 0)", "index out of range: -1 (array length 2)"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestPrintBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`print("a"); print("b")`, "ab"},
+		{`println("a"); println("b")`, "a\nb\n"},
+		{`println("sum:", 1 + 2, [1, 2], true)`, "sum: 3 [1, 2] true\n"},
+		{`println()`, "\n"},
+		{`let f = fn(x) { println(x) }; f(1); f(2)`, "1\n2\n"},
+		{`puts("a", 1, [2, 3])`, "a\n1\n[2, 3]\n"},
+		{`puts()`, "\n"},
+		{`puts("x"); print("y")`, "x\ny"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := EvalWithOutput(program, object.NewEnvironment(), &out)
+		testNullObject(t, evaluated)
+		if out.String() != tt.expected {
+			t.Errorf("wrong output for %s. expected=%q, got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	resolver := MapResolver{"greet": `let greet = fn() { print("hi") }; print("loading ")`}
+	env := object.NewEnvironment()
+	SetOutput(env, &out)
+	SetImportResolver(env, resolver, "")
+	Eval(parser.New(lexer.New(`import "greet"; greet()`)).ParseProgram(), env)
+	if out.String() != "loading hi" {
+		t.Errorf("imported code printed to the wrong place. got=%q", out.String())
+	}
+}
+
+func TestStringIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected any
+	}{
+		{`"hello"[0]`, "h"},
+		{`"hello"[1]`, "e"},
+		{`"hello"[4]`, "o"},
+		{`let s = "hello"; s[len(s) - 1]`, "o"},
+		{`"héllo"[1]`, "é"},
+		{`"hi 👋!"[3]`, "👋"},
+		{`let café = "crème brûlée"; café`, "crème brûlée"},
+		{`"日本語"[2]`, "語"},
+		{`"hello"[5]`, nil},
+		{`"hello"[-1]`, nil},
+		{`""[0]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		expected, ok := tt.expected.(string)
+		if ok {
+			testStringObject(t, evaluated, expected)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestSubstr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`substr("hello world", 0, 5)`, "hello"},
+		{`substr("hello world", 6, 5)`, "world"},
+		{`substr("hello", 1, 0)`, ""},
+		{`substr("hello", 3, 10)`, "lo"},
+		{`substr("hello", 10, 2)`, ""},
+		{`substr("héllo wörld", 1, 4)`, "éllo"},
+		{`substr("日本語テキスト", 3, 4)`, "テキスト"},
+		{`substr("abc", 1, 9223372036854775807)`, "bc"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`substr("abc", 1)`, "wrong number of arguments. got=2, want=3"},
+		{`substr(1, 0, 1)`, "first argument to `substr` must be STRING, got INTEGER"},
+		{`substr("abc", "0", 1)`, "start and length arguments to `substr` must be INTEGER, got STRING and INTEGER"},
+		{`substr("abc", -1, 1)`, "start and length arguments to `substr` must not be negative, got -1 and 1"},
+		{`substr("abc", 0, -1)`, "start and length arguments to `substr` must not be negative, got 0 and -1"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1, 2, 3]; a[1] = 99; a;", "[1, 99, 3]"},
+		{"let a = [1, 2, 3]; a[0] = a[0] + a[2]; a;", "[4, 2, 3]"},
+		{"let a = [1, 2, 3]; a[2] = 7;", "7"},
+		{"let grid = [[0, 0], [0, 0]]; grid[1][0] = 5; grid;", "[[0, 0], [5, 0]]"},
+		{"let a = [1]; let b = a; b[0] = 2; a;", "[2]"},
+		{"let a = [0, 0]; let set = fn(arr) { arr[1] = 1; }; set(a); a;", "[0, 1]"},
+		{`let h = {"a": 1}; h["a"] = 2; h["a"];`, "2"},
+		{`let h = {}; h["b"] = true; h["b"];`, "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%+v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestIndexAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1, 2, 3]; a[3] = 4;", "index out of range: 3 (array length 3)"},
+		{"let a = [1, 2, 3]; a[-1] = 4;", "index out of range: -1 (array length 3)"},
+		{`let a = [1]; a["0"] = 4;`, "array index must be INTEGER, got STRING"},
+		{`let s = "abc"; s[0] = "z";`, "index assignment not supported: STRING"},
+		{"let n = 5; n[0] = 1;", "index assignment not supported: INTEGER"},
+		{`let h = {}; h[[1]] = 1;`, "unusable as hash key: ARRAY"},
+		{"missing[0] = 1;", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][2:]", "[3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][:]", "[1, 2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:-1]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4, 5][-10:2]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][3:100]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][4:1]", "[]"},
+		{"[][0:1]", "[]"},
+		{`"hello world"[6:]`, "world"},
+		{`"héllo"[1:3]`, "él"},
+		{`"hello"[-3:-1]`, "ll"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%+v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	original := testEval("let a = [1, 2, 3]; let b = a[0:2]; a;")
+	if original.Inspect() != "[1, 2, 3]" {
+		t.Errorf("slicing mutated the original array. got=%s", original.Inspect())
+	}
+}
+
+func TestSliceExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2][true:]`, "slice index must be INTEGER, got BOOLEAN"},
+		{`[1, 2][:"a"]`, "slice index must be INTEGER, got STRING"},
+		{`{"a": 1}[0:1]`, "slice operator not supported: HASH"},
+		{`5[0:1]`, "slice operator not supported: INTEGER"},
+		{`[1][missing:]`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d",
+			len(result.Elements))
+	}
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{
+			"[1, 2, 3][0]",
 			1,
 		},
 		{
@@ -464,3 +2284,380 @@ func TestArrayIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestTupleLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"()", "()"},
+		{"(1,)", "(1,)"},
+		{"(1, 2 * 2, \"three\")", "(1, 4, three)"},
+		{"let t = (1, 2, 3); t[1:]", "(2, 3)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		tuple, ok := evaluated.(*object.Tuple)
+		if !ok {
+			t.Errorf("object is not Tuple. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if tuple.Inspect() != tt.expected {
+			t.Errorf("tuple has wrong elements. got=%s, want=%s",
+				tuple.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestTupleIndexAndLen(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"(1, 2, 3)[0]", 1},
+		{"let t = (1, 2, 3); t[2]", 3},
+		{"(1, 2, 3)[3]", nil},
+		{"(1,)[-1]", nil},
+		{"len((1, 2, 3))", 3},
+		{"len(())", 0},
+		{`let h = {(1, "a"): 10, (1,): 20}; h[(1, "a")]`, 10},
+		{`let h = {(1,): 20}; h[("1",)]`, nil},
+		{"len(set((1, 2), (1, 2), (2, 1)))", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTupleErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"let t = (1, 2); t[0] = 5", "cannot assign to an element of a TUPLE: tuples are immutable"},
+		{"{(1, [2]): 1}", "unusable as hash key: TUPLE"},
+		{"set((1, fn() {}))", "unusable as set element: TUPLE"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+
+	p := parser.New(lexer.New("(a, b) = (1, 2)"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("assigning to a tuple did not produce a parser error")
+	}
+}
+
+func TestMultipleReturnValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn() { return 1, 2, 3 }; f()", "(1, 2, 3)"},
+		{"let f = fn() { return 1, 2 }; let a, b = f(); [b, a]", "[2, 1]"},
+		{"let a, b = [1, 2]; a + b", "3"},
+		{"let a, b = (1, 2); [a, b]", "[1, 2]"},
+		{"let a, b = 1, 2; let a, b = b, a; [a, b]", "[2, 1]"},
+		{"let sumdiff = fn(x, y) { return x + y, x - y }; let s, d = sumdiff(7, 2); s * 10 + d", "95"},
+		{"let a, b = 1, 2; [a, b]", "[1, 2]"},
+		{"let f = fn() { return 1, 2 }; let a, b = f(); let a, b = b, a; [a, b]", "[2, 1]"},
+		{"let x = 1; let f = fn() { let x, y = x + 1, x + 2; [x, y] }; f()", "[2, 3]"},
+		{"let pair = [1, 2]; let [a, b] = pair; [b, a]", "[2, 1]"},
+		{"let [a, b, c] = [1]; [a, b, c]", "[1, null, null]"},
+		{"let [a] = [1, 2, 3]; a", "1"},
+		{"let [head, ...tail] = [1, 2, 3]; [head, tail]", "[1, [2, 3]]"},
+		{"let [a, b, ...rest] = [1]; [a, b, rest]", "[1, null, []]"},
+		{"let [...all] = []; all", "[]"},
+		{"let a = 1; let b = 2; let a, b = b, a; [a, b]", "[2, 1]"},
+		{"let f = fn() { let a = 1; let b = 2; let a, b = b, a; [a, b] }; f()", "[2, 1]"},
+		{"let a = 1; let b = [2, 3]; let [a, b] = b; [a, b]", "[2, 3]"},
+		{"let a = 1; let a, b = a + 1, a + 2; [a, b]", "[2, 3]"},
+		{"let xs = [1, 2]; let [...copy] = xs; copy[0] = 9; xs", "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("%q evaluated to nil", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: got=%s, want=%s", tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"let t = (1, 2, 3); let a, b = t", "cannot destructure 3 values into 2 names"},
+		{"let a, b = 5", "cannot destructure INTEGER into 2 names"},
+		{"let [a, b] = (1, 2)", "cannot destructure TUPLE with an array pattern"},
+		{"let [a, ...a] = [1, 2]", "identifier 'a' has already been declared"},
+		{"const [a] = [1]; a = 2", "cannot reassign constant 'a'"},
+		{"const a = 1; let a, b = 2, 3", "cannot reassign constant 'a'"},
+		{"const b = 1; let a = 0; let a, b = 2, 3; a", "cannot reassign constant 'b'"},
+		{"let a, a = 1, 2", "identifier 'a' has already been declared"},
+		{"const a, b = 1, 2; a = 3", "cannot reassign constant 'a'"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.Object]int64{
+		&object.String{Value: "one"}:   1,
+		&object.String{Value: "two"}:   2,
+		&object.String{Value: "three"}: 3,
+		&object.Integer{Value: 4}:      4,
+		TRUE:                           5,
+		FALSE:                          6,
+	}
+
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
+	}
+
+	for expectedKey, expectedValue := range expected {
+		value, ok := result.Get(expectedKey)
+		if !ok {
+			t.Errorf("no pair for key %s in hash", expectedKey.Inspect())
+			continue
+		}
+
+		testIntegerObject(t, value, expectedValue)
+	}
+}
+
+func TestKeysBuiltin(t *testing.T) {
+	evaluated := testEval(`keys({"a": 1, 2: "b", true: 3})`)
+	arr, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	found := map[string]bool{}
+	for _, key := range arr.Elements {
+		found[string(key.Type())+":"+key.Inspect()] = true
+	}
+	for _, expected := range []string{"STRING:a", "INTEGER:2", "BOOLEAN:true"} {
+		if !found[expected] {
+			t.Errorf("keys did not return %s. got=%v", expected, found)
+		}
+	}
+	if len(arr.Elements) != 3 {
+		t.Errorf("keys returned wrong number of elements. got=%d", len(arr.Elements))
+	}
+
+	testIntegerObject(t, testEval(`len(keys({}))`), 0)
+
+	errObj, ok := testEval(`keys([1])`).(*object.Error)
+	if !ok || errObj.Message != "argument to `keys` must be HASH, got ARRAY" {
+		t.Errorf("wrong error for keys([1]). got=%+v", errObj)
+	}
+}
+
+func TestValuesAndHasBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`values({"a": 3, "b": 1, "c": 2})`, "[3, 1, 2]"},
+		{`values({})`, "[]"},
+		{`let h = {"a": 1, "b": 2, "c": 3}; let k = keys(h); let v = values(h); [h[k[0]] == v[0], h[k[1]] == v[1], h[k[2]] == v[2]]`, "[true, true, true]"},
+		{`keys({"x": 1, "y": 2})`, "[x, y]"},
+		{`keys({"y": 2, "x": 1})`, "[y, x]"},
+		{`keys({3: "c", -1: "a", 2: "b", true: "d", "k": "e"})`, "[3, -1, 2, true, k]"},
+		{`let h = {"b": 1, "a": 2}; h["c"] = 3; h["b"] = 4; [keys(h), values(h)]`, "[[b, a, c], [4, 2, 3]]"},
+		{`let h = {"a": 1, "b": 2, "c": 3}; h = delete(h, "b"); h["b"] = 5; keys(h)`, "[a, c, b]"},
+		{`let h = {}; for (let i = 9; i >= 0; i--) { h["k" + str(i)] = i }; values(h)`, "[9, 8, 7, 6, 5, 4, 3, 2, 1, 0]"},
+		{`has({"a": 1}, "a")`, "true"},
+		{`has({"a": 1}, "b")`, "false"},
+		{`has({"a": null}, "a")`, "true"},
+		{`has({1: 1}, "1")`, "false"},
+		{`has({(1, 2): 1}, (1, 2))`, "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%+v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`values([1])`, "argument to `values` must be HASH, got ARRAY"},
+		{`values({}, {})`, "wrong number of arguments. got=2, want=1"},
+		{`has([1], 0)`, "first argument to `has` must be HASH, got ARRAY"},
+		{`has({})`, "wrong number of arguments. got=1, want=2"},
+		{`has({}, [1])`, "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range errorTests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok || errObj.Message != tt.expected {
+			t.Errorf("wrong error for %s. expected=%q, got=%+v", tt.input, tt.expected, errObj)
+		}
+	}
+}
+
+func TestHashAssignmentAndDelete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let h = {"k": 1}; h["k"] = 2; h["k"];`, "2"},
+		{`let h = {}; h["k"] = "v"; h["k"];`, "v"},
+		{`let h = {}; h[1] = "one"; h[true] = "yes"; len(keys(h));`, "2"},
+		{`let h = {"k": 1}; delete(h, "k");`, "{}"},
+		{`let h = {"k": 1}; delete(h, "missing");`, "{k: 1}"},
+		{`let h = {"k": 1, "j": 2}; delete(h, "k")["k"];`, "null"},
+		{`let h = {"k": 1, "j": 2}; delete(h, "k")["j"];`, "2"},
+		{`let h = {"k": 1, "j": 2}; delete(h, "k"); h["k"];`, "1"},
+		{`let h = {"k": 1}; let g = delete(h, "k"); g["k"] = 3; h["k"];`, "1"},
+		{`let h = {"k": 1}; let g = delete(h, "missing"); g["k"] = 3; h["k"];`, "1"},
+		{`let h = {"k": 1}; h = delete(h, "k"); len(delete(h, "k"));`, "0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%+v", tt.input, tt.expected, evaluated)
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`let h = {}; h[[1, 2]] = 3;`, "unusable as hash key: ARRAY"},
+		{`let h = {}; h[fn() {}] = 3;`, "unusable as hash key: FUNCTION"},
+		{`delete({}, [1])`, "unusable as hash key: ARRAY"},
+		{`delete([1], 0)`, "first argument to `delete` must be HASH, got ARRAY"},
+		{`delete({})`, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range errorTests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`{1: "one", "1": 2}["1"]`, 2},
+		{`{"outer": {"inner": 7}}["outer"]["inner"]`, 7},
+		{`let config = {"db": {"port": 5432}}; config["db"]["port"]`, 5432},
+		{`{"list": [1, 2, 3]}["list"][2]`, 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestHashKeyErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{`{[1, 2]: "pair"}`, "unusable as hash key: ARRAY"},
+		{`{{}: 1}`, "unusable as hash key: HASH"},
+		{`{"a": 1}[[1]]`, "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)",
+				evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"sort"
+)
+
+// The higher-order builtins call back into the evaluator through
+// applyFunction, which reaches the builtins map again, so they are added
+// in init instead of the map literal to avoid an initialization cycle.
+func init() {
+	builtins["map"] = &object.Builtin{EnvFn: arrayMap}
+	builtins["filter"] = &object.Builtin{EnvFn: arrayFilter}
+	builtins["reduce"] = &object.Builtin{EnvFn: arrayReduce}
+	builtins["sort"] = &object.Builtin{EnvFn: arraySort}
+}
+
+// callbackArgs checks the array and function arguments shared by the
+// higher-order builtins.
+func callbackArgs(name string, args []object.Object, want int) (*object.Array, object.Object, *object.Error) {
+	if len(args) != want {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, nil, newError("first argument to `%s` must be ARRAY, got %s",
+			name, args[0].Type())
+	}
+	if !isCallable(args[1]) {
+		return nil, nil, newError("second argument to `%s` must be FUNCTION, got %s",
+			name, args[1].Type())
+	}
+	return arr, args[1], nil
+}
+
+func isCallable(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Function, *object.Builtin:
+		return true
+	default:
+		return false
+	}
+}
+
+// arrayMap returns a new array holding fn applied to each element.
+func arrayMap(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArgs("map", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+
+	elements := make([]object.Object, 0, len(arr.Elements))
+	for _, el := range arr.Elements {
+		val := applyFunction(fn, []object.Object{el}, env)
+		if isError(val) {
+			return val
+		}
+		elements = append(elements, val)
+	}
+	return &object.Array{Elements: elements}
+}
+
+// arrayFilter returns a new array of the elements fn returns a truthy
+// value for.
+func arrayFilter(env *object.Environment, args ...object.Object) object.Object {
+	arr, fn, errObj := callbackArgs("filter", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+
+	elements := []object.Object{}
+	for _, el := range arr.Elements {
+		keep := applyFunction(fn, []object.Object{el}, env)
+		if isError(keep) {
+			return keep
+		}
+		if isTruthy(keep) {
+			elements = append(elements, el)
+		}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// arrayReduce folds the array from the left, calling fn with the
+// accumulator and each element in turn, starting from initial. Both
+// reduce(arr, fn, initial) and reduce(arr, initial, fn) are accepted; when
+// both are functions, the second argument is the callback.
+func arrayReduce(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) == 3 && !isCallable(args[1]) && isCallable(args[2]) {
+		args = []object.Object{args[0], args[2], args[1]}
+	}
+	arr, fn, errObj := callbackArgs("reduce", args, 3)
+	if errObj != nil {
+		return errObj
+	}
+
+	acc := args[2]
+	for _, el := range arr.Elements {
+		acc = applyFunction(fn, []object.Object{acc, el}, env)
+		if isError(acc) {
+			return acc
+		}
+	}
+	return acc
+}
+
+// arraySort returns a sorted copy of the array. Without a comparator the
+// elements must be all numbers or all strings; with one, fn(a, b) returns
+// whether a goes before b, or a negative, zero or positive integer. Equal
+// elements keep their order.
+func arraySort(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2",
+			len(args))
+	}
+
+	var arr *object.Array
+	var less func(a, b object.Object) (bool, object.Object)
+	if len(args) == 2 {
+		var fn object.Object
+		var errObj *object.Error
+		arr, fn, errObj = callbackArgs("sort", args, 2)
+		if errObj != nil {
+			return errObj
+		}
+		less = func(a, b object.Object) (bool, object.Object) {
+			result := applyFunction(fn, []object.Object{a, b}, env)
+			if isError(result) {
+				return false, result
+			}
+			switch order := result.(type) {
+			case *object.Boolean:
+				return order.Value, nil
+			case *object.Integer:
+				return order.Value < 0, nil
+			default:
+				return false, newError("comparator passed to `sort` must return BOOLEAN or INTEGER, got %s",
+					result.Type())
+			}
+		}
+	} else {
+		var ok bool
+		arr, ok = args[0].(*object.Array)
+		if !ok {
+			return newError("first argument to `sort` must be ARRAY, got %s",
+				args[0].Type())
+		}
+		var errObj *object.Error
+		less, errObj = naturalOrder(arr.Elements)
+		if errObj != nil {
+			return errObj
+		}
+	}
+
+	elements := make([]object.Object, len(arr.Elements))
+	copy(elements, arr.Elements)
+
+	var failed object.Object
+	sort.SliceStable(elements, func(i, j int) bool {
+		if failed != nil {
+			return false
+		}
+		result, errObj := less(elements[i], elements[j])
+		if errObj != nil {
+			failed = errObj
+		}
+		return result
+	})
+	if failed != nil {
+		return failed
+	}
+	return &object.Array{Elements: elements}
+}
+
+// naturalOrder returns the ordering sort uses without a comparator:
+// numeric for numbers, of either kind, and lexicographic for strings.
+func naturalOrder(elements []object.Object) (func(a, b object.Object) (bool, object.Object), *object.Error) {
+	if len(elements) == 0 {
+		return nil, nil
+	}
+
+	switch first := elements[0]; {
+	case isNumber(first):
+		for _, el := range elements {
+			if !isNumber(el) {
+				return nil, newError("cannot sort mixed types: %s and %s", first.Type(), el.Type())
+			}
+		}
+		return func(a, b object.Object) (bool, object.Object) {
+			return numberLess(a, b), nil
+		}, nil
+	case first.Type() == object.STRING_OBJ:
+		for _, el := range elements {
+			if el.Type() != object.STRING_OBJ {
+				return nil, newError("cannot sort mixed types: %s and %s", first.Type(), el.Type())
+			}
+		}
+		return func(a, b object.Object) (bool, object.Object) {
+			return a.(*object.String).Value < b.(*object.String).Value, nil
+		}, nil
+	default:
+		return nil, newError("cannot sort %s without a comparator", first.Type())
+	}
+}
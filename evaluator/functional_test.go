@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestHigherOrderBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`map([1, 2, 3], fn(x) { x * 2 })`, "[2, 4, 6]"},
+		{`map([], fn(x) { x })`, "[]"},
+		{`map(["a", "bc"], len)`, "[1, 2]"},
+		{`let a = [1, 2]; map(a, fn(x) { x + 1 }); a`, "[1, 2]"},
+		{`filter([1, 2, 3, 4], fn(x) { x > 2 })`, "[3, 4]"},
+		{`filter([1, null, false, 0], fn(x) { x })`, "[1, 0]"},
+		{`filter([1, 2], fn(x) { false })`, "[]"},
+		{`reduce([1, 2, 3], fn(acc, x) { acc + x }, 0)`, "6"},
+		{`reduce([], fn(acc, x) { acc + x }, 10)`, "10"},
+		{`reduce(["a", "b"], fn(acc, x) { x + acc }, "")`, "ba"},
+		{`reduce([[1], [2]], fn(acc, x) { push(acc, first(x)) }, [])`, "[1, 2]"},
+		{`reduce([1, 2, 3], 0, fn(acc, x) { acc + x })`, "6"},
+		{`reduce([], "empty", fn(acc, x) { acc + x })`, "empty"},
+		{`reduce([2, 3], 1, fn(acc, x) { acc * x })`, "6"},
+		{`let inc = fn(x) { x + 1 }; reduce([inc, inc], fn(acc, f) { fn(x) { f(acc(x)) } }, inc)(0)`, "3"},
+		{`filter([], fn(x) { true })`, "[]"},
+		{`map([], fn(x, y) { x })`, "[]"},
+		{`let calls = 0; try { map([1, 2, 3], fn(x) { calls += 1; if (x == 2) { throw "x" } x }) } catch (e) { calls }`, "2"},
+		{`let double = fn(x) { x * 2 }; map(filter([1, 2, 3], fn(x) { x != 2 }), double)`, "[2, 6]"},
+		{`let n = 10; map([1], fn(x) { x + n })`, "[11]"},
+		{`sort([3, 1, 2])`, "[1, 2, 3]"},
+		{`sort([])`, "[]"},
+		{`sort([2.5, 1, -3.5])`, "[-3.5, 1, 2.5]"},
+		{`sort(["pear", "apple", "Fig"])`, "[Fig, apple, pear]"},
+		{`sort([3, 1, 2], fn(a, b) { b - a })`, "[3, 2, 1]"},
+		{`let a = [3, 1, 2]; sort(a); a`, "[3, 1, 2]"},
+		{`sort([[1, "a"], [0, "b"], [1, "c"], [0, "d"]], fn(a, b) { a[0] - b[0] })`, "[[0, b], [0, d], [1, a], [1, c]]"},
+		{`sort(["bb", "a", "cc", "d"], fn(a, b) { len(a) - len(b) })`, "[a, d, bb, cc]"},
+		{`sort([3, 1, 2], fn(a, b) { a > b })`, "[3, 2, 1]"},
+		{`let people = [{"name": "ada", "age": 36}, {"name": "alan", "age": 41}, {"name": "grace", "age": 36}, {"name": "linus", "age": 21}]; map(sort(people, fn(a, b) { a["age"] < b["age"] }), fn(p) { p["name"] })`, "[linus, ada, grace, alan]"},
+		{`sort(["bb", "a", "cc", "d", "b"], fn(a, b) { len(a) < len(b) })`, "[a, d, b, bb, cc]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || isError(evaluated) {
+			t.Errorf("%s returned %+v", tt.input, evaluated)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %s. got=%s, want=%s",
+				tt.input, evaluated.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestHigherOrderBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`map([1])`, "wrong number of arguments. got=1, want=2"},
+		{`filter([1], fn(x) { x }, 1)`, "wrong number of arguments. got=3, want=2"},
+		{`reduce([1], fn(a, x) { a })`, "wrong number of arguments. got=2, want=3"},
+		{`map("abc", len)`, "first argument to `map` must be ARRAY, got STRING"},
+		{`filter({}, fn(x) { x })`, "first argument to `filter` must be ARRAY, got HASH"},
+		{`reduce([1], 1, 0)`, "second argument to `reduce` must be FUNCTION, got INTEGER"},
+		{`map([1, "a"], fn(x) { -x })`, "unknown operator: -STRING"},
+		{`map([1], fn(x, y) { x })`, "wrong number of arguments. got=1, want=2"},
+		{`filter([1], fn(x) { throw "no" })`, "no"},
+		{`reduce([1], 0, 1)`, "second argument to `reduce` must be FUNCTION, got INTEGER"},
+		{`reduce([1, 2], 0, fn(x) { x })`, "wrong number of arguments. got=2, want=1"},
+		{`filter([1, 2], fn() { true })`, "wrong number of arguments. got=1, want=0"},
+		{`reduce([1, 2], 0, fn(acc, x) { if (x == 2) { throw "stopped at 2" } acc + x })`, "stopped at 2"},
+		{`sort()`, "wrong number of arguments. got=0, want=1 or 2"},
+		{`sort("cba")`, "first argument to `sort` must be ARRAY, got STRING"},
+		{`sort([1], "desc")`, "second argument to `sort` must be FUNCTION, got STRING"},
+		{`sort([1, "a"])`, "cannot sort mixed types: INTEGER and STRING"},
+		{`sort(["a", 1.5])`, "cannot sort mixed types: STRING and FLOAT"},
+		{`sort([true, false])`, "cannot sort BOOLEAN without a comparator"},
+		{`sort([1, 2], fn(a, b) { "less" })`, "comparator passed to `sort` must return BOOLEAN or INTEGER, got STRING"},
+		{`sort([1, 2], fn(a, b) { null })`, "comparator passed to `sort` must return BOOLEAN or INTEGER, got NULL"},
+		{`sort([1, 2], fn(a, b) { throw "bad" })`, "bad"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package evaluator
+
+import (
+	"bananaScript/ast"
+	"bananaScript/lexer"
+	"bananaScript/object"
+	"bananaScript/parser"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportResolver finds the source of the files named by import
+// statements. from is the name of the importing file, "" for code that
+// did not come from an import, and path is the string given to import.
+// The returned name identifies the file: it is passed back as from for
+// imports inside it and is used to detect import cycles.
+type ImportResolver interface {
+	Resolve(from, path string) (name, source string, err error)
+}
+
+// FileResolver reads imports from the filesystem, resolving a relative
+// path against the directory of the importing file. Only .bs and .banana
+// files are imported, and a path without an extension tries both. When
+// Root is set, top-level imports are resolved against it instead of the
+// working directory and no import may reach outside it.
+type FileResolver struct {
+	Root string
+}
+
+var importExtensions = []string{".bs", ".banana"}
+
+func (fr FileResolver) Resolve(from, path string) (string, string, error) {
+	dir := fr.Root
+	if from != "" {
+		dir = filepath.Dir(from)
+	}
+	if fr.Root != "" && filepath.IsAbs(path) {
+		return "", "", fmt.Errorf("absolute paths cannot be imported")
+	}
+	full := path
+	if !filepath.IsAbs(path) {
+		full = filepath.Join(dir, path)
+	}
+
+	if fr.Root != "" {
+		rel, err := filepath.Rel(fr.Root, full)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", "", fmt.Errorf("%s is outside the import directory", path)
+		}
+	}
+
+	candidates := []string{full}
+	switch filepath.Ext(full) {
+	case ".bs", ".banana":
+	case "":
+		candidates = nil
+		for _, ext := range importExtensions {
+			candidates = append(candidates, full+ext)
+		}
+	default:
+		return "", "", fmt.Errorf("only .bs and .banana files can be imported")
+	}
+
+	for _, name := range candidates {
+		source, err := os.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		return name, string(source), nil
+	}
+	return "", "", fmt.Errorf("no such file")
+}
+
+// MapResolver serves imports from memory, mapping each import path to its
+// source. Paths are used as given, without resolving them relative to the
+// importing file.
+type MapResolver map[string]string
+
+func (mr MapResolver) Resolve(from, path string) (string, string, error) {
+	source, ok := mr[path]
+	if !ok {
+		return "", "", fmt.Errorf("no such file")
+	}
+	return path, source, nil
+}
+
+// DisabledResolver rejects every import.
+type DisabledResolver struct{}
+
+func (DisabledResolver) Resolve(from, path string) (string, string, error) {
+	return "", "", fmt.Errorf("import is disabled")
+}
+
+// evalImportStatement declares each top-level binding of the imported
+// file in env.
+func evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	moduleEnv, errObj := loadModule(is.Path, env)
+	if errObj != nil {
+		return errObj
+	}
+
+	for _, binding := range moduleEnv.Names() {
+		val, _ := moduleEnv.Get(binding)
+		bind := env.Declare
+		if moduleEnv.IsConstantInScope(binding) {
+			bind = env.DeclareConst
+		}
+		if err := bind(binding, val); err != nil {
+			return newError("cannot import %q: %s", is.Path, err)
+		}
+	}
+	return nil
+}
+
+// evalImportExpression returns the top-level bindings of the imported
+// file as a hash from their names to their values.
+func evalImportExpression(ie *ast.ImportExpression, env *object.Environment) object.Object {
+	path := Eval(ie.Path, env)
+	if isError(path) {
+		return path
+	}
+	str, ok := path.(*object.String)
+	if !ok {
+		return newError("argument to `import` must be STRING, got %s", path.Type())
+	}
+
+	moduleEnv, errObj := loadModule(str.Value, env)
+	if errObj != nil {
+		return errObj
+	}
+
+	module := object.NewHash()
+	for _, binding := range moduleEnv.Names() {
+		val, _ := moduleEnv.Get(binding)
+		module.Set(&object.String{Value: binding}, val)
+	}
+	return module
+}
+
+// loadModule returns the environment the file named by path was
+// evaluated in, evaluating it first in a fresh environment of its own
+// unless an earlier import in the same run already did. Without a
+// resolver, import is disabled.
+func loadModule(path string, env *object.Environment) (*object.Environment, object.Object) {
+	parent := stateOf(env)
+	resolver := parent.resolver
+	if resolver == nil {
+		resolver = DisabledResolver{}
+	}
+
+	name, source, err := resolver.Resolve(parent.file, path)
+	if err != nil {
+		return nil, newError("cannot import %q: %s", path, err)
+	}
+	if moduleEnv, ok := parent.modules[name]; ok {
+		return moduleEnv, nil
+	}
+	if parent.importing(name) {
+		return nil, newError("cannot import %q: import cycle through %s", path, name)
+	}
+
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, newError("cannot import %q: %s", path, strings.Join(p.Errors(), "; "))
+	}
+
+	moduleEnv := object.NewEnvironment()
+	moduleEnv.SetEvalState(&evalState{
+		runOptions: parent.runOptions,
+		resolver:   resolver,
+		file:       name,
+		parent:     parent,
+		callDepth:  parent.callDepth,
+		modules:    parent.modules,
+	})
+	if result := Eval(program, moduleEnv); isError(result) {
+		return nil, result
+	}
+	parent.modules[name] = moduleEnv
+	return moduleEnv, nil
+}
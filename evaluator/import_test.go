@@ -0,0 +1,198 @@
+package evaluator
+
+import (
+	"bananaScript/lexer"
+	"bananaScript/object"
+	"bananaScript/parser"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testEvalWithResolver(input string, resolver ImportResolver, file string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return EvalWithResolver(program, env, resolver, file)
+}
+
+func TestImportStatement(t *testing.T) {
+	resolver := MapResolver{
+		"math":   `let square = fn(x) { x * x }; const TAU = 6; let hidden = fn() { TAU }`,
+		"nested": `import "math"; let cube = fn(x) { x * square(x) }`,
+	}
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`import "math"; square(4)`, 16},
+		{`import "math"; TAU`, 6},
+		{`import "math"; hidden()`, 6},
+		{`import "nested"; cube(2) + square(1)`, 9},
+		{`let f = fn() { import "math"; square(3) }; f()`, 9},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEvalWithResolver(tt.input, resolver, ""), tt.expected)
+	}
+}
+
+func TestImportExpression(t *testing.T) {
+	resolver := MapResolver{
+		"math":    `let square = fn(x) { x * x }; const TAU = 6`,
+		"counter": `println("loading"); let count = [0]`,
+		"uses":    `let m = import("math"); let fourth = fn(x) { m["square"](m["square"](x)) }`,
+	}
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`let m = import("math"); m["square"](4)`, 16},
+		{`import("math")["TAU"]`, 6},
+		{`let name = "ma"; import(name + "th")["TAU"]`, 6},
+		{`len(import("math"))`, 2},
+		{`import("uses")["fourth"](2)`, 16},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEvalWithResolver(tt.input, resolver, ""), tt.expected)
+	}
+
+	evaluated := testEvalWithResolver(`let m = import("math"); square(2)`, resolver, "")
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != "identifier not found: square" {
+		t.Errorf("import() brought the bindings into scope. got=%+v", evaluated)
+	}
+
+	var out bytes.Buffer
+	program := parser.New(lexer.New(`
+let a = import("counter");
+let b = import("counter");
+import "counter";
+a["count"] == b["count"]
+`)).ParseProgram()
+	env := object.NewEnvironment()
+	SetOutput(env, &out)
+	testBooleanObject(t, EvalWithResolver(program, env, resolver, ""), true)
+	if out.String() != "loading\n" {
+		t.Errorf("module imported three times was not evaluated once. printed=%q", out.String())
+	}
+}
+
+func TestImportedModuleRunsInLaterRuns(t *testing.T) {
+	resolver := MapResolver{"m": `let hi = fn() { println("hi"); 1 }`}
+	env := object.NewEnvironment()
+	SetImportResolver(env, resolver, "")
+
+	var first, second bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	SetOutput(env, &first)
+	program := parser.New(lexer.New(`let m = import("m"); m["hi"]()`)).ParseProgram()
+	testIntegerObject(t, EvalWithContext(ctx, program, env), 1)
+	cancel()
+
+	SetOutput(env, &second)
+	program = parser.New(lexer.New(`m["hi"]()`)).ParseProgram()
+	testIntegerObject(t, EvalWithContext(context.Background(), program, env), 1)
+	if first.String() != "hi\n" || second.String() != "hi\n" {
+		t.Errorf("imported function did not print to the current run. first=%q, second=%q",
+			first.String(), second.String())
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	resolver := MapResolver{
+		"math":   `let square = fn(x) { x * x }; const TAU = 6`,
+		"broken": `let = 1`,
+		"fails":  `let x = missing`,
+		"a":      `import "b"`,
+		"b":      `import "a"`,
+		"c":      `let d = import("d")`,
+		"d":      `let c = import("c")`,
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "nope"`, `cannot import "nope": no such file`},
+		{`import "broken"`, `cannot import "broken": expected next token to be IDENT, got = instead; no prefix parse function for = found`},
+		{`import "fails"`, "identifier not found: missing"},
+		{`import "a"`, `cannot import "a": import cycle through a`},
+		{`import("c")`, `cannot import "c": import cycle through c`},
+		{`import(1)`, "argument to `import` must be STRING, got INTEGER"},
+		{`import("nope")`, `cannot import "nope": no such file`},
+		{`let square = 1; import "math"`, `cannot import "math": identifier 'square' has already been declared`},
+		{`import "math"; TAU = 7`, "cannot reassign constant 'TAU'"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithResolver(tt.input, resolver, "")
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+
+	errObj, ok := testEval(`import "math"`).(*object.Error)
+	if !ok || errObj.Message != `cannot import "math": import is disabled` {
+		t.Errorf("import without a resolver was not disabled. got=%+v", errObj)
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.bs":            `import "lib/util"; double(21)`,
+		"lib/util.bs":        `import "helpers.banana"; let double = fn(x) { add(x, x) }`,
+		"lib/helpers.bs":     `let add = fn(a, b) { a - b }`,
+		"lib/helpers.banana": `let add = fn(a, b) { a + b }`,
+		"notes.txt":          `let x = 1`,
+	}
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	main := filepath.Join(dir, "main.bs")
+	testIntegerObject(t, testEvalWithResolver(files["main.bs"], FileResolver{}, main), 42)
+	testIntegerObject(t, testEvalWithResolver(`import "lib/util.bs"; double(2)`, FileResolver{Root: dir}, ""), 4)
+
+	tests := []struct {
+		input    string
+		resolver ImportResolver
+		expected string
+	}{
+		{`import "notes.txt"`, FileResolver{Root: dir}, `cannot import "notes.txt": only .bs and .banana files can be imported`},
+		{`import "../secret"`, FileResolver{Root: dir}, `cannot import "../secret": ../secret is outside the import directory`},
+		{`import "/etc/passwd"`, FileResolver{Root: dir}, `cannot import "/etc/passwd": absolute paths cannot be imported`},
+		{`import "missing"`, FileResolver{Root: dir}, `cannot import "missing": no such file`},
+		{`import "lib/util"`, DisabledResolver{}, `cannot import "lib/util": import is disabled`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalWithResolver(tt.input, tt.resolver, "")
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
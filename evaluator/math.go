@@ -0,0 +1,162 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"math"
+)
+
+// numberArg checks that the argument to name at position i is an Integer
+// or a Float.
+func numberArg(name string, args []object.Object, i int) *object.Error {
+	switch {
+	case isNumber(args[i]):
+		return nil
+	case len(args) == 1:
+		return newError("argument to `%s` must be a number, got %s", name, args[i].Type())
+	case i < len(ordinals):
+		return newError("%s argument to `%s` must be a number, got %s", ordinals[i], name, args[i].Type())
+	default:
+		return newError("argument %d to `%s` must be a number, got %s", i+1, name, args[i].Type())
+	}
+}
+
+func mathAbs(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	if errObj := numberArg("abs", args, 0); errObj != nil {
+		return errObj
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Value == math.MinInt64 {
+			return newError("integer overflow: abs(%d)", arg.Value)
+		}
+		if arg.Value < 0 {
+			return &object.Integer{Value: -arg.Value}
+		}
+		return arg
+	default:
+		return &object.Float{Value: math.Abs(toFloat(arg))}
+	}
+}
+
+func mathSqrt(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	if errObj := numberArg("sqrt", args, 0); errObj != nil {
+		return errObj
+	}
+
+	value := toFloat(args[0])
+	if value < 0 {
+		return newError("cannot take the square root of a negative number: %s", args[0].Inspect())
+	}
+	return &object.Float{Value: math.Sqrt(value)}
+}
+
+// mathPow raises base to exp with the rules of `^`: two integers give an
+// Integer, failing on overflow or a negative exponent, and anything else
+// gives a Float.
+func mathPow(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2",
+			len(args))
+	}
+	for i := range args {
+		if errObj := numberArg("pow", args, i); errObj != nil {
+			return errObj
+		}
+	}
+
+	base, baseOk := args[0].(*object.Integer)
+	exp, expOk := args[1].(*object.Integer)
+	if baseOk && expOk {
+		return evalPowerExpression(base.Value, exp.Value)
+	}
+	return &object.Float{Value: math.Pow(toFloat(args[0]), toFloat(args[1]))}
+}
+
+func mathFloor(args ...object.Object) object.Object {
+	return roundToInteger("floor", math.Floor, args)
+}
+
+func mathCeil(args ...object.Object) object.Object {
+	return roundToInteger("ceil", math.Ceil, args)
+}
+
+// roundToInteger implements floor and ceil, which turn a Float into the
+// Integer round gives and return an Integer unchanged.
+func roundToInteger(name string, round func(float64) float64, args []object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	if errObj := numberArg(name, args, 0); errObj != nil {
+		return errObj
+	}
+	if integer, ok := args[0].(*object.Integer); ok {
+		return integer
+	}
+
+	value := round(toFloat(args[0]))
+	// -2^63 is exact as a float64, but 2^63 is already out of range.
+	if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+		return newError("%s(%s) does not fit in an integer", name, args[0].Inspect())
+	}
+	return &object.Integer{Value: int64(value)}
+}
+
+func mathMin(args ...object.Object) object.Object {
+	return extremum("min", numberLess, args)
+}
+
+func mathMax(args ...object.Object) object.Object {
+	return extremum("max", func(a, b object.Object) bool { return numberLess(b, a) }, args)
+}
+
+// extremum returns the first argument no other argument is better than,
+// keeping it an Integer or a Float as it was passed. A single array
+// argument is compared element by element instead.
+func extremum(name string, better func(a, b object.Object) bool, args []object.Object) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want at least 1")
+	}
+	if arr, ok := args[0].(*object.Array); ok && len(args) == 1 {
+		if len(arr.Elements) == 0 {
+			return newError("argument to `%s` must not be an empty array", name)
+		}
+		for i, el := range arr.Elements {
+			if !isNumber(el) {
+				return newError("element %d of the array passed to `%s` must be a number, got %s", i, name, el.Type())
+			}
+		}
+		args = arr.Elements
+	}
+
+	var best object.Object
+	for i, arg := range args {
+		if errObj := numberArg(name, args, i); errObj != nil {
+			return errObj
+		}
+		if best == nil || better(arg, best) {
+			best = arg
+		}
+	}
+	return best
+}
+
+// numberLess orders two numbers, comparing integers exactly rather than
+// as floats.
+func numberLess(a, b object.Object) bool {
+	x, xok := a.(*object.Integer)
+	y, yok := b.(*object.Integer)
+	if xok && yok {
+		return x.Value < y.Value
+	}
+	return toFloat(a) < toFloat(b)
+}
@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"abs(-5)", 5},
+		{"abs(5)", 5},
+		{"abs(-2.5)", 2.5},
+		{"sqrt(16)", 4.0},
+		{"sqrt(2.25)", 1.5},
+		{"pow(2, 10)", 1024},
+		{"pow(2, 0)", 1},
+		{"pow(2.0, -1)", 0.5},
+		{"pow(-2, 3)", -8},
+		{"pow(-2, 63)", -9223372036854775808},
+		{"pow(2.0, 3)", 8.0},
+		{"pow(4, 0.5)", 2.0},
+		{"floor(2.7)", 2},
+		{"floor(-2.5)", -3},
+		{"ceil(2.1)", 3},
+		{"ceil(-2.5)", -2},
+		{"floor(7)", 7},
+		{"min(3, 1, 2)", 1},
+		{"max(3, 1, 2)", 3},
+		{"min(4)", 4},
+		{"min(2, 1.5)", 1.5},
+		{"max(2, 1.5)", 2},
+		{"max(1, 1.0)", 1},
+		{"max(...[4, 9, 2])", 9},
+		{"max([4, 9, 2])", 9},
+		{"min([3, -1.5, 2])", -1.5},
+		{"min([-7])", -7},
+		{"abs(-0.0)", 0.0},
+		{"sqrt(9)", 3.0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestMathBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`abs("1")`, "argument to `abs` must be a number, got STRING"},
+		{`abs(1, 2)`, "wrong number of arguments. got=2, want=1"},
+		{"abs(-9223372036854775807 - 1)", "integer overflow: abs(-9223372036854775808)"},
+		{"sqrt(-4)", "cannot take the square root of a negative number: -4"},
+		{"sqrt(null)", "argument to `sqrt` must be a number, got NULL"},
+		{`pow(2, "3")`, "second argument to `pow` must be a number, got STRING"},
+		{"pow(2, 64)", "integer overflow: 2 ^ 64"},
+		{"pow(2, -1)", "negative exponent not supported for integers: 2 ^ -1"},
+		{"pow(-2, 64)", "integer overflow: -2 ^ 64"},
+		{"floor(true)", "argument to `floor` must be a number, got BOOLEAN"},
+		{"ceil(2.0 ^ 63)", "ceil(9223372036854776000) does not fit in an integer"},
+		{"min()", "wrong number of arguments. got=0, want at least 1"},
+		{`max(1, 2, 3, "4")`, "argument 4 to `max` must be a number, got STRING"},
+		{"max()", "wrong number of arguments. got=0, want at least 1"},
+		{"min(...[])", "wrong number of arguments. got=0, want at least 1"},
+		{"max([])", "argument to `max` must not be an empty array"},
+		{`min([1, "2"])`, "element 1 of the array passed to `min` must be a number, got STRING"},
+		{"min([1, 2], 3)", "first argument to `min` must be a number, got ARRAY"},
+		{"sqrt(-0.5)", "cannot take the square root of a negative number: -0.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error for %s. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package evaluator
+
+import "bananaScript/object"
+
+func setNew(args ...object.Object) object.Object {
+	set := object.NewSet()
+	for _, arg := range args {
+		if !set.Add(arg) {
+			return newError("unusable as set element: %s", arg.Type())
+		}
+	}
+	return set
+}
+
+// setAdd adds every value after the set to it in place and returns the set.
+func setAdd(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError("wrong number of arguments. got=%d, want at least 2",
+			len(args))
+	}
+
+	set, ok := args[0].(*object.Set)
+	if !ok {
+		return newError("first argument to `set_add` must be SET, got %s",
+			args[0].Type())
+	}
+	for _, arg := range args[1:] {
+		if !set.Add(arg) {
+			return newError("unusable as set element: %s", arg.Type())
+		}
+	}
+	return set
+}
+
+// setRemove removes every value after the set from it in place and
+// returns the set.
+func setRemove(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError("wrong number of arguments. got=%d, want at least 2",
+			len(args))
+	}
+
+	set, ok := args[0].(*object.Set)
+	if !ok {
+		return newError("first argument to `set_remove` must be SET, got %s",
+			args[0].Type())
+	}
+	for _, arg := range args[1:] {
+		if _, ok := object.AsHashable(arg); !ok {
+			return newError("unusable as set element: %s", arg.Type())
+		}
+		set.Remove(arg)
+	}
+	return set
+}
+
+func setUnion(args ...object.Object) object.Object {
+	a, b, errObj := setOperands("set_union", args)
+	if errObj != nil {
+		return errObj
+	}
+
+	result := object.NewSet()
+	for _, el := range a.Elements() {
+		result.Add(el)
+	}
+	for _, el := range b.Elements() {
+		result.Add(el)
+	}
+	return result
+}
+
+func setIntersection(args ...object.Object) object.Object {
+	a, b, errObj := setOperands("set_intersection", args)
+	if errObj != nil {
+		return errObj
+	}
+
+	result := object.NewSet()
+	for _, el := range a.Elements() {
+		if b.Has(el) {
+			result.Add(el)
+		}
+	}
+	return result
+}
+
+func setDifference(args ...object.Object) object.Object {
+	a, b, errObj := setOperands("set_difference", args)
+	if errObj != nil {
+		return errObj
+	}
+
+	result := object.NewSet()
+	for _, el := range a.Elements() {
+		if !b.Has(el) {
+			result.Add(el)
+		}
+	}
+	return result
+}
+
+func setOperands(name string, args []object.Object) (*object.Set, *object.Set, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError("wrong number of arguments. got=%d, want=2",
+			len(args))
+	}
+
+	a, ok := args[0].(*object.Set)
+	if !ok {
+		return nil, nil, newError("first argument to `%s` must be SET, got %s",
+			name, args[0].Type())
+	}
+	b, ok := args[1].(*object.Set)
+	if !ok {
+		return nil, nil, newError("second argument to `%s` must be SET, got %s",
+			name, args[1].Type())
+	}
+	return a, b, nil
+}
+
+func evalSetIndexExpression(set, index object.Object) object.Object {
+	if _, ok := object.AsHashable(index); !ok {
+		return newError("unusable as set element: %s", index.Type())
+	}
+	return nativeBoolToBooleanObject(set.(*object.Set).Has(index))
+}
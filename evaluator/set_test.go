@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestSetBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`set()`, "{}"},
+		{`set(3, 1, 2, 1, 3)`, "{1, 2, 3}"},
+		{`set(10, 2, "b", "a", true)`, "{true, 2, 10, a, b}"},
+		{`set_add(set(1), 2, 1)`, "{1, 2}"},
+		{`let s = set(1, 2, 3); set_remove(s, 2, 4); s`, "{1, 3}"},
+		{`set_union(set(1, 2), set(2, 3))`, "{1, 2, 3}"},
+		{`set_intersection(set(1, 2, 3), set(2, 3, 4))`, "{2, 3}"},
+		{`set_difference(set(1, 2, 3), set(2, 4))`, "{1, 3}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		set, ok := evaluated.(*object.Set)
+		if !ok {
+			t.Errorf("object is not Set. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if set.Inspect() != tt.expected {
+			t.Errorf("set has wrong elements. got=%s, want=%s",
+				set.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestSetMembership(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`set("a", "b")["a"]`, true},
+		{`set("a", "b")["c"]`, false},
+		{`set(1, "1")[1]`, true},
+		{`let s = set(); set_add(s, 5); s[5]`, true},
+		{`len(set(1, 1, 2))`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		}
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`set(1, [2])`, "unusable as set element: ARRAY"},
+		{`set_add(set(), {})`, "unusable as set element: HASH"},
+		{`set(1)[fn(x) { x }]`, "unusable as set element: FUNCTION"},
+		{`set_add([1], 2)`, "first argument to `set_add` must be SET, got ARRAY"},
+		{`set_remove(set(1))`, "wrong number of arguments. got=1, want at least 2"},
+		{`set_union(set(), [1])`, "second argument to `set_union` must be SET, got ARRAY"},
+		{`set_difference(set())`, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package evaluator
+
+import (
+	"bananaScript/ast"
+	"bananaScript/object"
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// maxCallDepth bounds how deeply function calls may nest, so that runaway
+// recursion fails with an error instead of overflowing the goroutine stack.
+var maxCallDepth = 1000
+
+// evalState is what one run of the evaluator carries alongside the
+// environment. Imported files get a state of their own that shares the
+// run options, call depth and module cache with their importer.
+type evalState struct {
+	*runOptions
+
+	// resolver finds the source of imports, and file names the source
+	// this code came from, for relative imports.
+	resolver ImportResolver
+	file     string
+
+	// parent is the state of the import that loaded file, followed to
+	// detect import cycles.
+	parent *evalState
+
+	// callDepth counts the function calls in progress.
+	callDepth *int
+
+	// modules holds the files already imported, by the name their
+	// resolver gave them.
+	modules map[string]*object.Environment
+}
+
+// runOptions are the parts of a state that callers set before each run.
+// They are shared by pointer, so a file imported by an earlier run is
+// cancelled with, and prints to, the run that calls into it now.
+type runOptions struct {
+	// ctx cancels the run.
+	ctx context.Context
+
+	// maxSleep is the longest one sleep may take, or 0 for no limit.
+	maxSleep time.Duration
+
+	// out is where print writes, and in is where input reads from, nil
+	// when there is no input.
+	out io.Writer
+	in  *bufio.Reader
+}
+
+func newEvalState() *evalState {
+	return &evalState{
+		runOptions: &runOptions{ctx: context.Background(), out: os.Stdout},
+		callDepth:  new(int),
+		modules:    map[string]*object.Environment{},
+	}
+}
+
+// stateOf returns the state attached to env, or a state with no deadline
+// and no imports if there is none.
+func stateOf(env *object.Environment) *evalState {
+	if state, ok := env.EvalState().(*evalState); ok {
+		return state
+	}
+	return newEvalState()
+}
+
+// ensureState attaches a fresh state to env unless it already has one,
+// so that a whole program run shares a single call depth.
+func ensureState(env *object.Environment) {
+	if _, ok := env.EvalState().(*evalState); !ok {
+		env.SetEvalState(newEvalState())
+	}
+}
+
+func (s *evalState) importing(name string) bool {
+	for ; s != nil; s = s.parent {
+		if s.file == name {
+			return true
+		}
+	}
+	return false
+}
+
+// EvalWithContext evaluates node like Eval, but stops with an error once
+// ctx is done. The context is checked before every loop iteration and
+// function call.
+func EvalWithContext(ctx context.Context, node ast.Node, env *object.Environment) object.Object {
+	ensureState(env)
+	stateOf(env).ctx = ctx
+	return Eval(node, env)
+}
+
+// SetMaxSleep limits every sleep in code evaluated in env to max, so that
+// a server can bound how long one request may idle. A sleep beyond it
+// fails. With 0, sleep is not limited.
+func SetMaxSleep(env *object.Environment, max time.Duration) {
+	ensureState(env)
+	stateOf(env).maxSleep = max
+}
+
+// SetOutput makes print and println in code evaluated in env write to out
+// instead of standard output.
+func SetOutput(env *object.Environment, out io.Writer) {
+	ensureState(env)
+	stateOf(env).out = out
+}
+
+// EvalWithOutput evaluates node like Eval, with print and println writing
+// to out.
+func EvalWithOutput(node ast.Node, env *object.Environment, out io.Writer) object.Object {
+	SetOutput(env, out)
+	return Eval(node, env)
+}
+
+// SetInput makes input in code evaluated in env read lines from in. With
+// a nil reader, as in the API, input fails instead of waiting for a line.
+func SetInput(env *object.Environment, in io.Reader) {
+	var reader *bufio.Reader
+	if in != nil {
+		var ok bool
+		if reader, ok = in.(*bufio.Reader); !ok {
+			reader = bufio.NewReader(in)
+		}
+	}
+	ensureState(env)
+	stateOf(env).in = reader
+}
+
+// SetImportResolver makes imports in code evaluated in env resolve with
+// resolver. file names the source the code came from, for relative
+// imports, and may be "".
+func SetImportResolver(env *object.Environment, resolver ImportResolver, file string) {
+	state := *stateOf(env)
+	state.resolver = resolver
+	state.file = file
+	env.SetEvalState(&state)
+}
+
+// EvalWithResolver evaluates node like Eval, resolving its imports with
+// resolver as described for SetImportResolver.
+func EvalWithResolver(node ast.Node, env *object.Environment, resolver ImportResolver, file string) object.Object {
+	SetImportResolver(env, resolver, file)
+	return Eval(node, env)
+}
+
+// checkCancelled returns an error once the context of the run env belongs
+// to is done.
+func checkCancelled(env *object.Environment) *object.Error {
+	ctx := stateOf(env).ctx
+	select {
+	case <-ctx.Done():
+		return newFatalError("execution stopped: %s", ctx.Err())
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"bananaScript/lexer"
+	"bananaScript/object"
+	"bananaScript/parser"
+	"context"
+	"testing"
+	"time"
+)
+
+func testEvalWithContext(ctx context.Context, input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return EvalWithContext(ctx, program, env)
+}
+
+func TestEvalWithContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []string{
+		"while (true) {}",
+		"for (let i = 0; i < 10; i = i + 1) {}",
+		"do {} while (true)",
+		"let f = fn() { 1 }; f()",
+	}
+
+	for _, input := range tests {
+		evaluated := testEvalWithContext(ctx, input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)", input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != "execution stopped: context canceled" {
+			t.Errorf("wrong error message for %s. got=%q", input, errObj.Message)
+		}
+	}
+
+	testIntegerObject(t, testEvalWithContext(ctx, "1 + 2"), 3)
+}
+
+func TestEvalWithContextDeadline(t *testing.T) {
+	defer func(limit int) { maxLoopIterations = limit }(maxLoopIterations)
+	defer func(limit int) { maxCallDepth = limit }(maxCallDepth)
+	maxLoopIterations = 1 << 62
+	maxCallDepth = 1 << 30
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	evaluated := testEvalWithContext(ctx, "let n = 0; while (true) { n = n + 1 }")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("evaluation ran for %s after its deadline", elapsed)
+	}
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok || errObj.Message != "execution stopped: context deadline exceeded" {
+		t.Errorf("wrong result for a timed out loop. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	evaluated = testEvalWithContext(ctx, "let f = fn(n) { f(n + 1) }; f(0)")
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != "execution stopped: context deadline exceeded" {
+		t.Errorf("wrong result for runaway recursion. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	evaluated = testEvalWithContext(ctx, `try { while (true) {} } catch (e) { "caught: " + e }`)
+	if errObj, ok := evaluated.(*object.Error); !ok || errObj.Message != "execution stopped: context deadline exceeded" {
+		t.Errorf("try caught the deadline. got=%T(%+v)", evaluated, evaluated)
+	}
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	tests := []string{
+		"let f = fn(n) { f(n - 1) }; f(100000)",
+		"let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; isEven(5000)",
+		"let f = fn() { try { f() } finally { 0 } }; f()",
+	}
+
+	for _, input := range tests {
+		evaluated := testEval(input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %s. got=%T(%+v)", input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != "maximum call stack size exceeded" {
+			t.Errorf("wrong error message for %s. got=%q", input, errObj.Message)
+		}
+	}
+
+	mutual := "let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } }; let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } }; isEven(998)"
+	testBooleanObject(t, testEval(mutual), true)
+
+	// The depth is released as calls return, so one run can make many
+	// calls in sequence and a run that hit the limit leaves none behind.
+	env := object.NewEnvironment()
+	for _, input := range []string{
+		"let f = fn(n) { if (n > 0) { f(n - 1) } else { 0 } }",
+		"f(5000)",
+		"f(900) + f(900)",
+	} {
+		Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+	}
+	testIntegerObject(t, Eval(parser.New(lexer.New("f(900)")).ParseProgram(), env), 0)
+}
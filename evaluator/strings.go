@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"strings"
+)
+
+var ordinals = []string{"first", "second", "third"}
+
+// maxRepeatLength caps the strings `*` and repeat build, so that
+// `"-" * 999999999` fails instead of exhausting memory.
+var maxRepeatLength = 4 << 20
+
+// stringArgs checks that name was called with want arguments, all of them
+// strings, and returns their values.
+func stringArgs(name string, args []object.Object, want int) ([]string, *object.Error) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d",
+			len(args), want)
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			if want == 1 {
+				return nil, newError("argument to `%s` must be STRING, got %s",
+					name, arg.Type())
+			}
+			return nil, newError("%s argument to `%s` must be STRING, got %s",
+				ordinals[i], name, arg.Type())
+		}
+		values[i] = str.Value
+	}
+	return values, nil
+}
+
+// stringSplit splits a string around each delimiter, or into its
+// characters when the delimiter is empty.
+func stringSplit(args ...object.Object) object.Object {
+	values, errObj := stringArgs("split", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+
+	parts := strings.Split(values[0], values[1])
+	elements := make([]object.Object, len(parts))
+	for i, part := range parts {
+		elements[i] = &object.String{Value: part}
+	}
+	return &object.Array{Elements: elements}
+}
+
+func stringJoin(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2",
+			len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("first argument to `join` must be ARRAY, got %s",
+			args[0].Type())
+	}
+	sep, ok := args[1].(*object.String)
+	if !ok {
+		return newError("second argument to `join` must be STRING, got %s",
+			args[1].Type())
+	}
+
+	parts := make([]string, len(arr.Elements))
+	for i, el := range arr.Elements {
+		str, ok := el.(*object.String)
+		if !ok {
+			return newError("elements joined by `join` must be STRING, got %s at index %d",
+				el.Type(), i)
+		}
+		parts[i] = str.Value
+	}
+	return &object.String{Value: strings.Join(parts, sep.Value)}
+}
+
+func stringTrim(args ...object.Object) object.Object {
+	values, errObj := stringArgs("trim", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: strings.TrimSpace(values[0])}
+}
+
+func stringUpper(args ...object.Object) object.Object {
+	values, errObj := stringArgs("upper", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: strings.ToUpper(values[0])}
+}
+
+func stringLower(args ...object.Object) object.Object {
+	values, errObj := stringArgs("lower", args, 1)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: strings.ToLower(values[0])}
+}
+
+func stringContains(args ...object.Object) object.Object {
+	values, errObj := stringArgs("contains", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	return nativeBoolToBooleanObject(strings.Contains(values[0], values[1]))
+}
+
+func stringStartsWith(args ...object.Object) object.Object {
+	values, errObj := stringArgs("startsWith", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	return nativeBoolToBooleanObject(strings.HasPrefix(values[0], values[1]))
+}
+
+func stringEndsWith(args ...object.Object) object.Object {
+	values, errObj := stringArgs("endsWith", args, 2)
+	if errObj != nil {
+		return errObj
+	}
+	return nativeBoolToBooleanObject(strings.HasSuffix(values[0], values[1]))
+}
+
+// stringReplace replaces every occurrence of old, not just the first.
+func stringReplace(args ...object.Object) object.Object {
+	values, errObj := stringArgs("replace", args, 3)
+	if errObj != nil {
+		return errObj
+	}
+	return &object.String{Value: strings.ReplaceAll(values[0], values[1], values[2])}
+}
+
+// repeatString returns s repeated count times.
+func repeatString(s string, count int64) object.Object {
+	if count < 0 {
+		return newError("negative repeat count: %d", count)
+	}
+	if len(s) > 0 && count > int64(maxRepeatLength/len(s)) {
+		return newError("repeated string would exceed %d bytes", maxRepeatLength)
+	}
+	return &object.String{Value: strings.Repeat(s, int(count))}
+}
+
+func stringRepeat(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=2",
+			len(args))
+	}
+
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("first argument to `repeat` must be STRING, got %s",
+			args[0].Type())
+	}
+	count, ok := args[1].(*object.Integer)
+	if !ok {
+		return newError("second argument to `repeat` must be INTEGER, got %s",
+			args[1].Type())
+	}
+	return repeatString(str.Value, count.Value)
+}
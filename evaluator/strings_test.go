@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`split("a,b,,c", ",")`, []string{"a", "b", "", "c"}},
+		{`split("héllo", "")`, []string{"h", "é", "l", "l", "o"}},
+		{`split("", ",")`, []string{""}},
+		{`split("one two", " ")`, []string{"one", "two"}},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join([], ", ")`, ""},
+		{`join(split("a b c", " "), "")`, "abc"},
+		{"trim(\"  hi there \\n\\t\")", "hi there"},
+		{`upper("héllo")`, "HÉLLO"},
+		{`lower("HÉLLO")`, "héllo"},
+		{`contains("banana", "nan")`, true},
+		{`contains("banana", "")`, true},
+		{`contains("banana", "Nan")`, false},
+		{`replace("banana", "a", "o")`, "bonono"},
+		{`replace("banana", "x", "o")`, "banana"},
+		{`replace("", "", "x")`, "x"},
+		{`replace("ab", "", "-")`, "-a-b-"},
+		{`split("", "")`, []string{}},
+		{`join([""], ",")`, ""},
+		{`trim("")`, ""},
+		{`upper("")`, ""},
+		{`contains("", "")`, true},
+		{`contains("", "a")`, false},
+		{`startsWith("banana", "ban")`, true},
+		{`startsWith("banana", "nan")`, false},
+		{`startsWith("banana", "")`, true},
+		{`startsWith("", "a")`, false},
+		{`endsWith("banana", "nana")`, true},
+		{`endsWith("banana", "ban")`, false},
+		{`endsWith("", "")`, true},
+		{`"-" * 5`, "-----"},
+		{`3 * "ab"`, "ababab"},
+		{`"ab" * 0`, ""},
+		{`"" * 999999999`, ""},
+		{`"=" * 2 + "|"`, "==|"},
+		{`let line = "-"; line *= 3; line`, "---"},
+		{`repeat("hé", 2)`, "héhé"},
+		{`repeat("x", 0)`, ""},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case []string:
+			arr, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if len(arr.Elements) != len(expected) {
+				t.Errorf("wrong num of elements for %s. got=%d, want=%d",
+					tt.input, len(arr.Elements), len(expected))
+				continue
+			}
+			for i, want := range expected {
+				testStringObject(t, arr.Elements[i], want)
+			}
+		}
+	}
+}
+
+func TestStringBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`split("a")`, "wrong number of arguments. got=1, want=2"},
+		{`split(1, ",")`, "first argument to `split` must be STRING, got INTEGER"},
+		{`split("a", [","])`, "second argument to `split` must be STRING, got ARRAY"},
+		{`join("abc", "")`, "first argument to `join` must be ARRAY, got STRING"},
+		{`join(["a"], 1)`, "second argument to `join` must be STRING, got INTEGER"},
+		{`join(["a", 1], ",")`, "elements joined by `join` must be STRING, got INTEGER at index 1"},
+		{`trim(1)`, "argument to `trim` must be STRING, got INTEGER"},
+		{`upper("a", "b")`, "wrong number of arguments. got=2, want=1"},
+		{`lower(null)`, "argument to `lower` must be STRING, got NULL"},
+		{`contains(["a"], "a")`, "first argument to `contains` must be STRING, got ARRAY"},
+		{`replace("a", "a", 1)`, "third argument to `replace` must be STRING, got INTEGER"},
+		{`startsWith("a")`, "wrong number of arguments. got=1, want=2"},
+		{`startsWith(1, "a")`, "first argument to `startsWith` must be STRING, got INTEGER"},
+		{`endsWith("a", null)`, "second argument to `endsWith` must be STRING, got NULL"},
+		{`"-" * -1`, "negative repeat count: -1"},
+		{`"-" * 999999999`, "repeated string would exceed 4194304 bytes"},
+		{`999999999 * "ab"`, "repeated string would exceed 4194304 bytes"},
+		{`"-" * 1.5`, "type mismatch: STRING * FLOAT"},
+		{`"a" * "b"`, "unknown operator: STRING * STRING"},
+		{`repeat("a")`, "wrong number of arguments. got=1, want=2"},
+		{`repeat(1, 2)`, "first argument to `repeat` must be STRING, got INTEGER"},
+		{`repeat("a", "2")`, "second argument to `repeat` must be INTEGER, got STRING"},
+		{`repeat("ab", -2)`, "negative repeat count: -2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"time"
+)
+
+// timeNow returns the Unix time in seconds, with the fraction of a second
+// elapsed.
+func timeNow(args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0",
+			len(args))
+	}
+	return &object.Float{Value: float64(time.Now().UnixNano()) / float64(time.Second)}
+}
+
+// timeSleep pauses for the given number of milliseconds, waking early with
+// an error if the run is cancelled meanwhile.
+func timeSleep(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+	if errObj := numberArg("sleep", args, 0); errObj != nil {
+		return errObj
+	}
+
+	ms := toFloat(args[0])
+	if ms < 0 {
+		return newError("argument to `sleep` must not be negative, got %s", args[0].Inspect())
+	}
+	state := stateOf(env)
+	if state.maxSleep > 0 && ms > float64(state.maxSleep/time.Millisecond) {
+		return newError("sleep is limited to %dms, got %s", state.maxSleep/time.Millisecond, args[0].Inspect())
+	}
+
+	timer := time.NewTimer(time.Duration(ms * float64(time.Millisecond)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return NULL
+	case <-state.ctx.Done():
+		return newFatalError("execution stopped: %s", state.ctx.Err())
+	}
+}
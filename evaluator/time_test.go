@@ -0,0 +1,87 @@
+package evaluator
+
+import (
+	"bananaScript/lexer"
+	"bananaScript/object"
+	"bananaScript/parser"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeBuiltin(t *testing.T) {
+	before := float64(time.Now().UnixNano()) / float64(time.Second)
+	result, ok := testEval("time()").(*object.Float)
+	after := float64(time.Now().UnixNano()) / float64(time.Second)
+	if !ok {
+		t.Fatalf("time() did not return Float. got=%T", result)
+	}
+	if result.Value < before || result.Value > after {
+		t.Errorf("time() out of range. got=%f, want between %f and %f",
+			result.Value, before, after)
+	}
+
+	errObj, ok := testEval("time(1)").(*object.Error)
+	if !ok || errObj.Message != "wrong number of arguments. got=1, want=0" {
+		t.Errorf("wrong error for time(1). got=%+v", errObj)
+	}
+}
+
+func TestSleepBuiltin(t *testing.T) {
+	start := time.Now()
+	testNullObject(t, testEval("sleep(20)"))
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("sleep(20) returned after %s", elapsed)
+	}
+	testNullObject(t, testEval("sleep(0.5)"))
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`sleep()`, "wrong number of arguments. got=0, want=1"},
+		{`sleep(1, 2)`, "wrong number of arguments. got=2, want=1"},
+		{`sleep("1")`, "argument to `sleep` must be a number, got STRING"},
+		{`sleep(-1)`, "argument to `sleep` must not be negative, got -1"},
+	}
+
+	for _, tt := range tests {
+		errObj, ok := testEval(tt.input).(*object.Error)
+		if !ok {
+			t.Errorf("%s did not return an error", tt.input)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message for %s. expected=%q, got=%q",
+				tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestSleepIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	errObj, ok := testEvalWithContext(ctx, "sleep(60000)").(*object.Error)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sleep ran for %s past its deadline", elapsed)
+	}
+	if !ok || errObj.Message != "execution stopped: context deadline exceeded" {
+		t.Errorf("cancelled sleep did not fail. got=%+v", errObj)
+	}
+}
+
+func TestSleepLimit(t *testing.T) {
+	env := object.NewEnvironment()
+	SetMaxSleep(env, 10*time.Millisecond)
+
+	program := parser.New(lexer.New("sleep(11)")).ParseProgram()
+	errObj, ok := Eval(program, env).(*object.Error)
+	if !ok || errObj.Message != "sleep is limited to 10ms, got 11" {
+		t.Errorf("sleep over the limit did not fail. got=%+v", errObj)
+	}
+
+	program = parser.New(lexer.New("sleep(10)")).ParseProgram()
+	testNullObject(t, Eval(program, env))
+}
@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+func tomlParse(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `tomlParse` must be STRING, got %s",
+			args[0].Type())
+	}
+
+	var doc map[string]any
+	if _, err := toml.Decode(str.Value, &doc); err != nil {
+		return newError("could not parse TOML: %s", err)
+	}
+
+	return tomlToObject(doc)
+}
+
+func tomlStringify(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+
+	if args[0].Type() != object.HASH_OBJ {
+		return newError("argument to `tomlStringify` must be HASH, got %s",
+			args[0].Type())
+	}
+
+	doc, errObj := objectToToml(args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	var out bytes.Buffer
+	if err := toml.NewEncoder(&out).Encode(doc); err != nil {
+		return newError("could not encode TOML: %s", err)
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+func tomlToObject(value any) object.Object {
+	switch value := value.(type) {
+	case map[string]any:
+		hash := object.NewHash()
+		for _, k := range sortedKeys(value) {
+			v := value[k]
+			val := tomlToObject(v)
+			if isError(val) {
+				return val
+			}
+			hash.Set(&object.String{Value: k}, val)
+		}
+		return hash
+	case []map[string]any:
+		elements := make([]object.Object, 0, len(value))
+		for _, v := range value {
+			el := tomlToObject(v)
+			if isError(el) {
+				return el
+			}
+			elements = append(elements, el)
+		}
+		return &object.Array{Elements: elements}
+	case []any:
+		elements := make([]object.Object, 0, len(value))
+		for _, v := range value {
+			el := tomlToObject(v)
+			if isError(el) {
+				return el
+			}
+			elements = append(elements, el)
+		}
+		return &object.Array{Elements: elements}
+	case string:
+		return &object.String{Value: value}
+	case int64:
+		return &object.Integer{Value: value}
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case float64:
+		return &object.Float{Value: value}
+	case time.Time:
+		return &object.String{Value: formatTomlTime(value)}
+	default:
+		return newError("unsupported TOML value: %v", value)
+	}
+}
+
+// sortedKeys returns the keys of a decoded table in order, so that the
+// hash built from it lists them the same way every time.
+func sortedKeys(table map[string]any) []string {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatTomlTime renders TOML dates and times as strings, keeping local
+// dates and times free of the offset the decoder attaches to them.
+func formatTomlTime(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+func objectToToml(obj object.Object) (any, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Hash:
+		doc := make(map[string]any, obj.Len())
+		for _, pair := range obj.Pairs() {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, newError("TOML keys must be STRING, got %s",
+					pair.Key.Type())
+			}
+			val, err := objectToToml(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			doc[key.Value] = val
+		}
+		return doc, nil
+	case *object.Array:
+		elements := make([]any, 0, len(obj.Elements))
+		for _, el := range obj.Elements {
+			val, err := objectToToml(el)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, val)
+		}
+		return elements, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	default:
+		return nil, newError("cannot convert %s to TOML", obj.Type())
+	}
+}
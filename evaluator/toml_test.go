@@ -0,0 +1,154 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestTomlParseKeyValues(t *testing.T) {
+	doc := tomlParse(&object.String{Value: `
+title = "example"
+count = 42
+enabled = true
+`})
+
+	testStringObject(t, testHashValue(t, doc, "title"), "example")
+	testIntegerObject(t, testHashValue(t, doc, "count"), 42)
+	testBooleanObject(t, testHashValue(t, doc, "enabled"), true)
+}
+
+func TestTomlParseNestedSections(t *testing.T) {
+	doc := tomlParse(&object.String{Value: `
+[server]
+host = "localhost"
+
+[server.limits]
+connections = 10
+`})
+
+	server := testHashValue(t, doc, "server")
+	testStringObject(t, testHashValue(t, server, "host"), "localhost")
+
+	limits := testHashValue(t, server, "limits")
+	testIntegerObject(t, testHashValue(t, limits, "connections"), 10)
+}
+
+func TestTomlParseArrays(t *testing.T) {
+	doc := tomlParse(&object.String{Value: `
+ports = [8000, 8001]
+
+[[users]]
+name = "ada"
+
+[[users]]
+name = "linus"
+`})
+
+	ports, ok := testHashValue(t, doc, "ports").(*object.Array)
+	if !ok {
+		t.Fatalf("ports is not Array. got=%T", testHashValue(t, doc, "ports"))
+	}
+	if len(ports.Elements) != 2 {
+		t.Fatalf("ports has wrong num of elements. got=%d", len(ports.Elements))
+	}
+	testIntegerObject(t, ports.Elements[0], 8000)
+	testIntegerObject(t, ports.Elements[1], 8001)
+
+	users, ok := testHashValue(t, doc, "users").(*object.Array)
+	if !ok {
+		t.Fatalf("users is not Array. got=%T", testHashValue(t, doc, "users"))
+	}
+	if len(users.Elements) != 2 {
+		t.Fatalf("users has wrong num of elements. got=%d", len(users.Elements))
+	}
+	testStringObject(t, testHashValue(t, users.Elements[1], "name"), "linus")
+}
+
+func TestTomlParseIntegerAndFloat(t *testing.T) {
+	doc := tomlParse(&object.String{Value: "answer = 42"})
+	testIntegerObject(t, testHashValue(t, doc, "answer"), 42)
+
+	doc = tomlParse(&object.String{Value: "pi = 3.14"})
+	testFloatObject(t, testHashValue(t, doc, "pi"), 3.14)
+
+	out := tomlStringify(doc)
+	testStringObject(t, out, "pi = 3.14\n")
+}
+
+func TestTomlParseDates(t *testing.T) {
+	doc := tomlParse(&object.String{Value: `
+released = 1979-05-27T07:32:00Z
+birthday = 1979-05-27
+alarm = 07:32:00
+meeting = 1979-05-27T07:32:00
+`})
+
+	testStringObject(t, testHashValue(t, doc, "released"), "1979-05-27T07:32:00Z")
+	testStringObject(t, testHashValue(t, doc, "birthday"), "1979-05-27")
+	testStringObject(t, testHashValue(t, doc, "alarm"), "07:32:00")
+	testStringObject(t, testHashValue(t, doc, "meeting"), "1979-05-27T07:32:00")
+}
+
+func TestTomlRoundTrip(t *testing.T) {
+	source := &object.String{Value: `
+name = "bananaScript"
+tags = ["fruit", "script"]
+
+[owner]
+name = "kitatsu"
+age = 30
+`}
+
+	first := tomlStringify(tomlParse(source))
+	second := tomlStringify(tomlParse(first))
+
+	firstStr, ok := first.(*object.String)
+	if !ok {
+		t.Fatalf("tomlStringify did not return String. got=%T (%+v)", first, first)
+	}
+	testStringObject(t, second, firstStr.Value)
+}
+
+func TestTomlParseKeyOrder(t *testing.T) {
+	keys := testEval(`keys(tomlParse("b = 1\na = 2\nc = 3"))`)
+	if keys.Inspect() != "[a, b, c]" {
+		t.Errorf("wrong key order. got=%s", keys.Inspect())
+	}
+}
+
+func TestTomlErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`tomlParse(1)`, "argument to `tomlParse` must be STRING, got INTEGER"},
+		{`tomlParse("a", "b")`, "wrong number of arguments. got=2, want=1"},
+		{`tomlStringify("a")`, "argument to `tomlStringify` must be HASH, got STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+
+	key := &object.Integer{Value: 1}
+	hash := object.NewHash()
+	hash.Set(key, key)
+	errObj, ok := tomlStringify(hash).(*object.Error)
+	if !ok || errObj.Message != "TOML keys must be STRING, got INTEGER" {
+		t.Errorf("non-string key did not return the expected error. got=%+v", errObj)
+	}
+
+	parsed := tomlParse(&object.String{Value: "a = "})
+	if _, ok := parsed.(*object.Error); !ok {
+		t.Errorf("invalid TOML did not return an error. got=%T (%+v)", parsed, parsed)
+	}
+}
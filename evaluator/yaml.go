@@ -0,0 +1,202 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func yamlParse(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("argument to `yamlParse` must be STRING, got %s",
+			args[0].Type())
+	}
+
+	documents := []object.Object{}
+	decoder := yaml.NewDecoder(strings.NewReader(str.Value))
+	for {
+		var doc any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return newError("could not parse YAML: %s", err)
+		}
+
+		obj := yamlToObject(doc)
+		if isError(obj) {
+			return obj
+		}
+		documents = append(documents, obj)
+	}
+
+	switch len(documents) {
+	case 0:
+		return NULL
+	case 1:
+		return documents[0]
+	default:
+		return &object.Array{Elements: documents}
+	}
+}
+
+func yamlStringify(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1",
+			len(args))
+	}
+
+	doc, errObj := objectToYaml(args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return newError("could not encode YAML: %s", err)
+	}
+
+	return &object.String{Value: string(out)}
+}
+
+func yamlToObject(value any) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return NULL
+	case map[string]any:
+		hash := object.NewHash()
+		for _, k := range sortedKeys(value) {
+			v := value[k]
+			val := yamlToObject(v)
+			if isError(val) {
+				return val
+			}
+			hash.Set(&object.String{Value: k}, val)
+		}
+		return hash
+	case map[any]any:
+		hash := object.NewHash()
+		for _, k := range sortedYamlKeys(value) {
+			v := value[k]
+			key := yamlToObject(k)
+			if isError(key) {
+				return key
+			}
+			if _, ok := object.AsHashable(key); !ok {
+				return newError("unusable as hash key: %s", key.Type())
+			}
+			val := yamlToObject(v)
+			if isError(val) {
+				return val
+			}
+			hash.Set(key, val)
+		}
+		return hash
+	case []any:
+		elements := make([]object.Object, 0, len(value))
+		for _, v := range value {
+			el := yamlToObject(v)
+			if isError(el) {
+				return el
+			}
+			elements = append(elements, el)
+		}
+		return &object.Array{Elements: elements}
+	case string:
+		return &object.String{Value: value}
+	case int:
+		return &object.Integer{Value: int64(value)}
+	case uint64:
+		if value > math.MaxInt64 {
+			return newError("YAML integer out of range: %d", value)
+		}
+		return &object.Integer{Value: int64(value)}
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case float64:
+		return &object.Float{Value: value}
+	case time.Time:
+		return &object.String{Value: formatYamlTime(value)}
+	default:
+		return newError("unsupported YAML value: %v", value)
+	}
+}
+
+// sortedYamlKeys returns the keys of a decoded mapping ordered by type and
+// then value, so that the hash built from it lists them the same way
+// every time.
+func sortedYamlKeys(mapping map[any]any) []any {
+	keys := make([]any, 0, len(mapping))
+	for k := range mapping {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprintf("%T %v", keys[i], keys[i]) < fmt.Sprintf("%T %v", keys[j], keys[j])
+	})
+	return keys
+}
+
+// formatYamlTime renders YAML timestamps as strings, dropping the clock
+// for plain dates like `2001-12-14`.
+func formatYamlTime(t time.Time) string {
+	if t.Location() == time.UTC && t.Hour() == 0 && t.Minute() == 0 &&
+		t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func objectToYaml(obj object.Object) (any, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Hash:
+		doc := make(map[any]any, obj.Len())
+		for _, pair := range obj.Pairs() {
+			key, err := objectToYaml(pair.Key)
+			if err != nil {
+				return nil, err
+			}
+			val, err := objectToYaml(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			doc[key] = val
+		}
+		return doc, nil
+	case *object.Array:
+		elements := make([]any, 0, len(obj.Elements))
+		for _, el := range obj.Elements {
+			val, err := objectToYaml(el)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, val)
+		}
+		return elements, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	default:
+		return nil, newError("cannot convert %s to YAML", obj.Type())
+	}
+}
@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"bananaScript/object"
+	"testing"
+)
+
+func TestYamlParseScalars(t *testing.T) {
+	testIntegerObject(t, yamlParse(&object.String{Value: "42"}), 42)
+	testFloatObject(t, yamlParse(&object.String{Value: "2.5"}), 2.5)
+	testStringObject(t, yamlParse(&object.String{Value: "hello"}), "hello")
+	testBooleanObject(t, yamlParse(&object.String{Value: "true"}), true)
+	testNullObject(t, yamlParse(&object.String{Value: "~"}))
+	testNullObject(t, yamlParse(&object.String{Value: ""}))
+	testStringObject(t, yamlParse(&object.String{Value: "2001-12-14"}), "2001-12-14")
+}
+
+func TestYamlParseNestedMappings(t *testing.T) {
+	doc := yamlParse(&object.String{Value: `
+server:
+  host: localhost
+  limits:
+    connections: 10
+1: one
+`})
+
+	server := testHashValue(t, doc, "server")
+	testStringObject(t, testHashValue(t, server, "host"), "localhost")
+	testIntegerObject(t, testHashValue(t, testHashValue(t, server, "limits"), "connections"), 10)
+
+	one, _ := doc.(*object.Hash).Get(&object.Integer{Value: 1})
+	testStringObject(t, one, "one")
+}
+
+func TestYamlParseSequences(t *testing.T) {
+	doc := yamlParse(&object.String{Value: `
+- 1
+- two
+- [3, 4]
+`})
+
+	arr, ok := doc.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", doc, doc)
+	}
+	if len(arr.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d", len(arr.Elements))
+	}
+	testIntegerObject(t, arr.Elements[0], 1)
+	testStringObject(t, arr.Elements[1], "two")
+
+	nested, ok := arr.Elements[2].(*object.Array)
+	if !ok || len(nested.Elements) != 2 {
+		t.Fatalf("nested sequence wrong. got=%T (%+v)", arr.Elements[2], arr.Elements[2])
+	}
+	testIntegerObject(t, nested.Elements[1], 4)
+}
+
+func TestYamlParseAnchorsAndMergeKeys(t *testing.T) {
+	doc := yamlParse(&object.String{Value: `
+base: &base
+  name: default
+  retries: 3
+copy: *base
+derived:
+  <<: *base
+  retries: 5
+`})
+
+	testStringObject(t, testHashValue(t, testHashValue(t, doc, "copy"), "name"), "default")
+
+	derived := testHashValue(t, doc, "derived")
+	testStringObject(t, testHashValue(t, derived, "name"), "default")
+	testIntegerObject(t, testHashValue(t, derived, "retries"), 5)
+}
+
+func TestYamlParseMultipleDocuments(t *testing.T) {
+	doc := yamlParse(&object.String{Value: "a: 1\n---\nb: 2\n"})
+
+	arr, ok := doc.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", doc, doc)
+	}
+	if len(arr.Elements) != 2 {
+		t.Fatalf("array has wrong num of documents. got=%d", len(arr.Elements))
+	}
+	testIntegerObject(t, testHashValue(t, arr.Elements[0], "a"), 1)
+	testIntegerObject(t, testHashValue(t, arr.Elements[1], "b"), 2)
+}
+
+func TestYamlRoundTrip(t *testing.T) {
+	source := &object.String{Value: `
+name: bananaScript
+tags: [fruit, script]
+owner:
+  name: kitatsu
+  age: 30
+  admin: true
+  email: null
+`}
+
+	parsed := yamlParse(source)
+	first := yamlStringify(parsed)
+	reparsed := yamlParse(first)
+
+	owner := testHashValue(t, reparsed, "owner")
+	testIntegerObject(t, testHashValue(t, owner, "age"), 30)
+	testBooleanObject(t, testHashValue(t, owner, "admin"), true)
+	testNullObject(t, testHashValue(t, owner, "email"))
+
+	firstStr, ok := first.(*object.String)
+	if !ok {
+		t.Fatalf("yamlStringify did not return String. got=%T (%+v)", first, first)
+	}
+	testStringObject(t, yamlStringify(reparsed), firstStr.Value)
+}
+
+func TestYamlParseKeyOrder(t *testing.T) {
+	keys := testEval(`keys(yamlParse("b: 1\na: 2\nc: 3"))`)
+	if keys.Inspect() != "[a, b, c]" {
+		t.Errorf("wrong key order. got=%s", keys.Inspect())
+	}
+
+	keys = testEval(`keys(yamlParse("2: b\n1: a\nx: c\ntrue: d"))`)
+	if keys.Inspect() != "[true, 1, 2, x]" {
+		t.Errorf("wrong key order for mixed keys. got=%s", keys.Inspect())
+	}
+}
+
+func TestYamlErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`yamlParse(1)`, "argument to `yamlParse` must be STRING, got INTEGER"},
+		{`yamlParse()`, "wrong number of arguments. got=0, want=1"},
+		{`yamlParse("a: [1,")`, "could not parse YAML: yaml: line 1: did not find expected node content"},
+		{`yamlStringify(fn(x) { x })`, "cannot convert FUNCTION to YAML"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expected, errObj.Message)
+		}
+	}
+}
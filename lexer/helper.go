@@ -1,15 +1,62 @@
 package lexer
 
-import "bananaScript/token"
+import (
+	"bananaScript/token"
+	"fmt"
+	"strings"
+	"unicode"
+)
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
+
+func isRadixPrefix(ch rune) bool {
+	switch ch {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return true
+	}
+	return false
+}
+
+func hasRadixPrefix(literal string) bool {
+	return len(literal) >= 2 && literal[0] == '0' && isRadixPrefix(rune(literal[1]))
+}
+
+var radixDigits = map[byte]struct {
+	name   string
+	digits string
+}{
+	'x': {"hexadecimal", "0123456789abcdefABCDEF"},
+	'o': {"octal", "01234567"},
+	'b': {"binary", "01"},
+}
+
+// checkRadixDigits validates the digits of a 0x, 0o or 0b literal. Digit
+// separators are left for the parser to check.
+func checkRadixDigits(literal string) error {
+	if !hasRadixPrefix(literal) {
+		return nil
+	}
+
+	radix := radixDigits[literal[1]|0x20]
+	digits := literal[2:]
+	if strings.Trim(digits, "_") == "" {
+		return fmt.Errorf("%s literal %q has no digits", radix.name, literal)
+	}
+
+	for _, ch := range digits {
+		if ch != '_' && !strings.ContainsRune(radix.digits, ch) {
+			return fmt.Errorf("invalid digit %q in %s literal %q", ch, radix.name, literal)
+		}
+	}
+	return nil
+}
@@ -2,49 +2,75 @@ package lexer
 
 import (
 	"bananaScript/token"
-	"log"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
+// Lexer reads its input one UTF-8 encoded rune at a time. position and
+// readPosition are byte offsets into input. line is the line l.ch is on
+// and lineStart the offset that line starts at; tokenLine and tokenColumn
+// are where the token being read starts.
 type Lexer struct {
 	input        string
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune
+	line         int
+	lineStart    int
+	tokenLine    int
+	tokenColumn  int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 
 	return l
 }
 
+// NextToken returns the next token along with the line and column it
+// starts at.
 func (l *Lexer) NextToken() token.Token {
+	tok := l.nextToken()
+	tok.Line = l.tokenLine
+	tok.Column = l.tokenColumn
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
 	var tok token.Token
 
 	l.skipWhitespace()
+	l.tokenLine = l.line
+	l.tokenColumn = utf8.RuneCountInString(l.input[l.lineStart:min(l.position, len(l.input))]) + 1
 
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.EQ, Literal: string(ch) + string(l.ch)}
+			tok = l.readTwoCharToken(token.EQ)
+		} else if l.peekChar() == '>' {
+			tok = l.readTwoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '!':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			tok = token.Token{Type: token.NOT_EQ, Literal: string(ch) + string(l.ch)}
+			tok = l.readTwoCharToken(token.NOT_EQ)
 		} else {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '/':
 		if l.peekChar() == '/' {
 			l.skipComment()
-			return l.NextToken() // Get the next non-comment token
+			return l.nextToken() // Get the next non-comment token
+		} else if l.peekChar() == '*' {
+			if err := l.skipBlockComment(); err != nil {
+				return token.Token{Type: token.ILLEGAL, Literal: err.Error()}
+			}
+			return l.nextToken()
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.SLASH_ASSIGN)
 		} else {
 			tok = newToken(token.SLASH, l.ch)
 		}
@@ -57,15 +83,53 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RPAREN, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case '?':
+		if l.peekChar() == '?' {
+			tok = l.readTwoCharToken(token.COALESCE)
+		} else {
+			tok = newToken(token.QUESTION, l.ch)
+		}
+	case ':':
+		tok = newToken(token.COLON, l.ch)
+	case '.':
+		if strings.HasPrefix(l.input[l.readPosition:], "..") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		if l.peekChar() == '+' {
+			tok = l.readTwoCharToken(token.PLUSPLUS)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.PLUS_ASSIGN)
+		} else {
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '-' {
+			tok = l.readTwoCharToken(token.MINUSMINUS)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.MINUS_ASSIGN)
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.ASTERISK_ASSIGN)
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
 	case '^':
 		tok = newToken(token.CARET, l.ch)
+	case '&':
+		tok = newToken(token.AMPERSAND, l.ch)
+	case '|':
+		tok = newToken(token.PIPE, l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
 
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
@@ -77,25 +141,41 @@ func (l *Lexer) NextToken() token.Token {
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '<' {
+			tok = l.readTwoCharToken(token.LSHIFT)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.LT_EQ)
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '>' {
+			tok = l.readTwoCharToken(token.RSHIFT)
+		} else if l.peekChar() == '=' {
+			tok = l.readTwoCharToken(token.GT_EQ)
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
-	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+	case '"', '\'':
+		tok = l.readStringToken()
 
 	default:
-		if isLetter(l.ch) {
+		if l.ch == '_' && isDigit(l.peekChar()) {
+			// Identifiers never contain digits, so this can only be a
+			// number with a misplaced separator; let the parser report it.
+			return l.readNumberToken()
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookUpIdent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+			return l.readNumberToken()
+		} else if l.invalidChar() {
+			tok = token.Token{Type: token.ILLEGAL,
+				Literal: fmt.Sprintf("invalid UTF-8 encoding at position %d", l.position)}
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -105,6 +185,12 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
+func (l *Lexer) readTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
+}
+
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
 		l.readChar()
@@ -119,51 +205,163 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readString() string {
+// readStringToken reads a string literal delimited by double or single
+// quotes; the other kind of quote may appear unescaped inside. Strings holding a
+// `${...}` interpolation come back as a TEMPLATE token carrying their raw
+// source for the parser to split; the rest have their escape sequences
+// decoded. An unknown escape does not stop the read, so lexing resumes
+// after the closing quote.
+func (l *Lexer) readStringToken() token.Token {
+	position := l.position + 1
+	interpolated, err := l.skipString()
+	if err != nil {
+		return token.Token{Type: token.ILLEGAL, Literal: err.Error()}
+	}
+
+	raw := l.input[position:l.position]
+	if interpolated {
+		if _, err := splitTemplate(raw, position); err != nil {
+			return token.Token{Type: token.ILLEGAL, Literal: err.Error()}
+		}
+		return token.Token{Type: token.TEMPLATE, Literal: raw}
+	}
+
+	str, err := unescape(raw, position)
+	if err != nil {
+		return token.Token{Type: token.ILLEGAL, Literal: err.Error()}
+	}
+	return token.Token{Type: token.STRING, Literal: str}
+}
+
+// skipString advances from an opening quote to the matching closing
+// quote, reporting whether the string holds any `${...}` interpolations.
+func (l *Lexer) skipString() (bool, error) {
 	position := l.position + 1
-	var result []byte
+	quote := l.ch
+	interpolated := false
 
 	for {
 		l.readChar()
 
-		if l.ch == 0x5c && l.peekChar() == '"' {
+		switch {
+		case l.ch == '\\':
 			l.readChar()
-			result = append(result, '"')
-		} else if l.ch == '"' {
-			break
-		} else if l.ch == 0 {
-			log.Fatalf("unterminated string literal at position %d", position)
-		} else {
-			result = append(result, l.ch)
+			if l.ch == 0 {
+				return false, fmt.Errorf("unterminated string literal at position %d", position)
+			}
+		case l.ch == '$' && l.peekChar() == '{':
+			if !l.skipInterpolation() {
+				return false, fmt.Errorf("unterminated ${ in string literal at position %d", position)
+			}
+			interpolated = true
+		case l.ch == quote:
+			return interpolated, nil
+		case l.invalidChar():
+			return false, fmt.Errorf("invalid UTF-8 encoding in string literal at position %d", position)
+		case l.ch == 0:
+			return false, fmt.Errorf("unterminated string literal at position %d", position)
 		}
 	}
-	return string(result)
 }
 
+// skipInterpolation advances from the `$` of a `${` to its matching `}`,
+// stepping over nested braces and string literals. It reports false if
+// the input ends first.
+func (l *Lexer) skipInterpolation() bool {
+	l.readChar()
+	depth := 1
+
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return true
+			}
+		case '"', '\'':
+			if _, err := l.skipString(); err != nil {
+				return false
+			}
+		case 0:
+			return false
+		}
+	}
+}
+
+// readNumberToken reads an integer or float literal, returning an ILLEGAL token when
+// a 0x, 0o or 0b literal has no digits or holds one outside its base.
+func (l *Lexer) readNumberToken() token.Token {
+	position := l.position
+	literal := l.readNumber()
+	if err := checkRadixDigits(literal); err != nil {
+		return token.Token{Type: token.ILLEGAL, Literal: err.Error()}
+	}
+	if !hasRadixPrefix(literal) && l.ch == '.' && isDigit(l.peekChar()) {
+		l.readChar()
+		l.readDecimalDigits()
+		return token.Token{Type: token.FLOAT, Literal: l.input[position:l.position]}
+	}
+	return token.Token{Type: token.INT, Literal: literal}
+}
+
+// readNumber reads a decimal literal or a 0x, 0o or 0b prefixed one, along
+// with any _ digit separators. After a prefix it consumes every letter and
+// digit, so that malformed literals like 0b102 are reported whole.
 func (l *Lexer) readNumber() string {
 	position := l.position
-	for isDigit(l.ch) {
+	if l.ch == '0' && isRadixPrefix(l.peekChar()) {
+		l.readChar()
+		l.readChar()
+		for isLetter(l.ch) || isDigit(l.ch) {
+			l.readChar()
+		}
+		return l.input[position:l.position]
+	}
+
+	return l.readDecimalDigits()
+}
+
+func (l *Lexer) readDecimalDigits() string {
+	position := l.position
+	for isDigit(l.ch) || l.ch == '_' {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.lineStart = l.readPosition
+	}
+	l.position = l.readPosition
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		l.readPosition++
+		return
 	}
-	l.position = l.readPosition
-	l.readPosition++
+
+	r, size := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	l.ch = r
+	l.readPosition += size
 }
 
-func (l *Lexer) peekChar() byte {
+// invalidChar reports whether l.ch stands in for a byte that is not valid
+// UTF-8, as opposed to a U+FFFD written out in the input.
+func (l *Lexer) invalidChar() bool {
+	return l.ch == utf8.RuneError && l.readPosition-l.position == 1
+}
+
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
-	} else {
-		return l.input[l.readPosition]
 	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
 }
 
 func (l *Lexer) skipComment() {
@@ -172,3 +370,27 @@ func (l *Lexer) skipComment() {
 		l.readChar()
 	}
 }
+
+// skipBlockComment skips a /* ... */ comment, leaving l.ch just past the
+// closing */. Block comments do not nest: a /* inside one is an error, and
+// lexing resumes after the first */ so the rest of the file still lexes.
+func (l *Lexer) skipBlockComment() error {
+	position := l.position
+	var err error
+
+	l.readChar()
+	for {
+		l.readChar()
+
+		switch {
+		case l.ch == 0:
+			return fmt.Errorf("unterminated block comment at position %d", position)
+		case l.ch == '*' && l.peekChar() == '/':
+			l.readChar()
+			l.readChar()
+			return err
+		case l.ch == '/' && l.peekChar() == '*' && err == nil:
+			err = fmt.Errorf("nested block comment at position %d", l.position)
+		}
+	}
+}
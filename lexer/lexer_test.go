@@ -12,7 +12,7 @@ let add = fn(x, y) {
 x + y;
 };
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 a1
 if (5 < 10) {
@@ -25,6 +25,20 @@ if (5 < 10) {
 "foobar"
 "foo \"bar";
 [1, 2];
+a & b | c ^ ~d;
+1 << 2 >> 1;
+1 <= 2 >= 1;
+x += 1; x -= 1; x *= 2; x /= 2;
+++x; x--;
+a ? b : c;
+a ?? b;
+fn(...rest) { f(...rest) };
+match (x) { _ => 1 }
+const PI = 3;
+for while do break continue xor
+0xFF 0o17 0b101 0b102 0x;
+1_000_000 0xFF_FF _5;
+3.14 0.5 [1].len 0x1.5;
 `
 
 	tests := []struct {
@@ -121,6 +135,411 @@ if (5 < 10) {
 		{token.RBRACKET, "]"},
 		{token.SEMICOLON, ";"},
 
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.PIPE, "|"},
+		{token.IDENT, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "d"},
+		{token.SEMICOLON, ";"},
+
+		{token.INT, "1"},
+		{token.LSHIFT, "<<"},
+		{token.INT, "2"},
+		{token.RSHIFT, ">>"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+
+		{token.INT, "1"},
+		{token.LT_EQ, "<="},
+		{token.INT, "2"},
+		{token.GT_EQ, ">="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+
+		{token.PLUSPLUS, "++"},
+		{token.IDENT, "x"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUSMINUS, "--"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "a"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "b"},
+		{token.COLON, ":"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+
+		{token.IDENT, "a"},
+		{token.COALESCE, "??"},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
+
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RPAREN, ")"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+
+		{token.CONST, "const"},
+		{token.IDENT, "PI"},
+		{token.ASSIGN, "="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+
+		{token.FOR, "for"},
+		{token.WHILE, "while"},
+		{token.DO, "do"},
+		{token.BREAK, "break"},
+		{token.CONTINUE, "continue"},
+		{token.XOR, "xor"},
+
+		{token.INT, "0xFF"},
+		{token.INT, "0o17"},
+		{token.INT, "0b101"},
+		{token.ILLEGAL, `invalid digit '2' in binary literal "0b102"`},
+		{token.ILLEGAL, `hexadecimal literal "0x" has no digits`},
+		{token.SEMICOLON, ";"},
+
+		{token.INT, "1_000_000"},
+		{token.INT, "0xFF_FF"},
+		{token.INT, "_5"},
+		{token.SEMICOLON, ";"},
+
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.RBRACKET, "]"},
+		{token.ILLEGAL, "."},
+		{token.IDENT, "len"},
+		{token.INT, "0x1"},
+		{token.ILLEGAL, "."},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+}
+
+func TestStringEscapeSequences(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"line\nbreak"`, "line\nbreak"},
+		{`"tab\there"`, "tab\there"},
+		{`"carriage\rreturn"`, "carriage\rreturn"},
+		{`"back\\slash"`, `back\slash`},
+		{`"nul\0byte"`, "nul\x00byte"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"\\n"`, `\n`},
+		{`"cost: \${price}"`, "cost: ${price}"},
+		{`"just $5"`, "just $5"},
+		{`'hello'`, "hello"},
+		{`'it\'s'`, "it's"},
+		{`'say "hi"'`, `say "hi"`},
+		{`"it's"`, "it's"},
+		{`'tab\there'`, "tab\there"},
+		{`''`, ""},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != token.STRING {
+			t.Fatalf("tokentype wrong for %s. expected=%q, got=%q (%q)",
+				tt.input, token.STRING, tok.Type, tok.Literal)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong for %s. expected=%q, got=%q",
+				tt.input, tt.expected, tok.Literal)
+		}
+	}
+}
+
+func TestIllegalStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"bad \q escape"`, `unknown escape sequence \q in string literal at position 1`},
+		{`let s = "unterminated`, "unterminated string literal at position 9"},
+		{`"trailing\`, "unterminated string literal at position 1"},
+		{`let s = 'unterminated`, "unterminated string literal at position 9"},
+		{`'mismatched"`, "unterminated string literal at position 1"},
+		{`'bad \q'`, `unknown escape sequence \q in string literal at position 1`},
+		{`"sum is ${a + b"`, "unterminated ${ in string literal at position 1"},
+		{`x = "${f("a")`, "unterminated ${ in string literal at position 5"},
+		{`"${a} bad \q"`, `unknown escape sequence \q in string literal at position 1`},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		for tok.Type != token.ILLEGAL && tok.Type != token.EOF {
+			tok = l.NextToken()
+		}
+
+		if tok.Type != token.ILLEGAL {
+			t.Fatalf("no ILLEGAL token for %s", tt.input)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong for %s. expected=%q, got=%q",
+				tt.input, tt.expected, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("expected EOF after %s. got=%q", tt.input, next.Type)
+		}
+	}
+}
+
+func TestUnicodeStringsAndComments(t *testing.T) {
+	input := `// コメント with ünïcödé
+let 🍌 = 1;
+let greeting = "héllo 👋 wörld"; /* 注释 🍌 */ 'ñ'
+"U+FFFD stays: �"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.ILLEGAL, "🍌"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "greeting"},
+		{token.ASSIGN, "="},
+		{token.STRING, "héllo 👋 wörld"},
+		{token.SEMICOLON, ";"},
+		{token.STRING, "ñ"},
+		{token.STRING, "U+FFFD stays: �"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = \xff;", "invalid UTF-8 encoding at position 8"},
+		{"caf\xc3", "invalid UTF-8 encoding at position 3"},
+		{"\"bad \xe2\x82 bytes\"", "invalid UTF-8 encoding in string literal at position 1"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		for tok.Type != token.ILLEGAL && tok.Type != token.EOF {
+			tok = l.NextToken()
+		}
+
+		if tok.Type != token.ILLEGAL {
+			t.Fatalf("no ILLEGAL token for %q", tt.input)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong for %q. expected=%q, got=%q",
+				tt.input, tt.expected, tok.Literal)
+		}
+	}
+}
+
+func TestBlockComments(t *testing.T) {
+	input := `let x = /* default */ 5;
+/*
+  spans
+  lines */
+x /**/ * /* "not a string" // nor a line comment */ 2;`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASTERISK, "*"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestIllegalBlockComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		next     token.TokenType
+	}{
+		{"let x = 5; /* never closed\nlet y = 6;", "unterminated block comment at position 11", token.EOF},
+		{"/* outer /* inner */ x", "nested block comment at position 9", token.IDENT},
+		{"/*/", "unterminated block comment at position 0", token.EOF},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		for tok.Type != token.ILLEGAL && tok.Type != token.EOF {
+			tok = l.NextToken()
+		}
+
+		if tok.Type != token.ILLEGAL {
+			t.Fatalf("no ILLEGAL token for %q", tt.input)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong for %q. expected=%q, got=%q",
+				tt.input, tt.expected, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != tt.next {
+			t.Errorf("wrong token after %q. expected=%q, got=%q", tt.input, tt.next, next.Type)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []TemplatePart
+	}{
+		{`"sum is ${a + b}"`, []TemplatePart{
+			{Text: "sum is "},
+			{Text: "a + b", IsExpression: true},
+		}},
+		{`"${x}\t${y}!"`, []TemplatePart{
+			{Text: "x", IsExpression: true},
+			{Text: "\t"},
+			{Text: "y", IsExpression: true},
+			{Text: "!"},
+		}},
+		{`'${x} and ${'y'}'`, []TemplatePart{
+			{Text: "x", IsExpression: true},
+			{Text: " and "},
+			{Text: "'y'", IsExpression: true},
+		}},
+		{`"${ {"k": "}"}["k"] } \${raw}"`, []TemplatePart{
+			{Text: ` {"k": "}"}["k"] `, IsExpression: true},
+			{Text: " ${raw}"},
+		}},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != token.TEMPLATE {
+			t.Fatalf("tokentype wrong for %s. expected=%q, got=%q (%q)",
+				tt.input, token.TEMPLATE, tok.Type, tok.Literal)
+		}
+
+		parts, err := SplitTemplate(tok.Literal)
+		if err != nil {
+			t.Fatalf("SplitTemplate(%q) returned error: %s", tok.Literal, err)
+		}
+		if len(parts) != len(tt.expected) {
+			t.Fatalf("wrong number of parts for %s. expected=%d, got=%d (%+v)",
+				tt.input, len(tt.expected), len(parts), parts)
+		}
+		for i, part := range parts {
+			if part != tt.expected[i] {
+				t.Errorf("part %d wrong for %s. expected=%+v, got=%+v",
+					i, tt.input, tt.expected[i], part)
+			}
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := `let café = "crème"; 変数 + π; "naïve \q" ü`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "café"},
+		{token.ASSIGN, "="},
+		{token.STRING, "crème"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "変数"},
+		{token.PLUS, "+"},
+		{token.IDENT, "π"},
+		{token.SEMICOLON, ";"},
+		{token.ILLEGAL, `unknown escape sequence \q in string literal at position 36`}, // a byte offset
+		{token.IDENT, "ü"},
 		{token.EOF, ""},
 	}
 
@@ -137,5 +556,43 @@ if (5 < 10) {
 				i, tt.expectedLiteral, tok.Literal)
 		}
 	}
+}
 
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x + \"two\nlines\" // comment\n/* a\nblock */ café(π)\r\nend"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"x", 2, 3},
+		{"+", 2, 5},
+		{"two\nlines", 2, 7},
+		{"café", 5, 10},
+		{"(", 5, 14},
+		{"π", 5, 15},
+		{")", 5, 16},
+		{"end", 6, 1},
+		{"", 6, 4},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position of %q wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLiteral, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
 }
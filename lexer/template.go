@@ -0,0 +1,103 @@
+package lexer
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// TemplatePart is one piece of an interpolated string: either decoded
+// literal text or the source of an embedded `${...}` expression.
+type TemplatePart struct {
+	Text         string
+	IsExpression bool
+}
+
+// SplitTemplate splits the raw source of a TEMPLATE token into its literal
+// text and embedded expressions, in order.
+func SplitTemplate(raw string) ([]TemplatePart, error) {
+	return splitTemplate(raw, 0)
+}
+
+func splitTemplate(raw string, position int) ([]TemplatePart, error) {
+	var parts []TemplatePart
+	l := New(raw)
+	literalStart := 0
+
+	flushLiteral := func(end int) error {
+		if end == literalStart {
+			return nil
+		}
+		text, err := unescape(raw[literalStart:end], position)
+		if err != nil {
+			return err
+		}
+		parts = append(parts, TemplatePart{Text: text})
+		return nil
+	}
+
+	for l.ch != 0 {
+		switch {
+		case l.ch == '\\':
+			l.readChar()
+		case l.ch == '$' && l.peekChar() == '{':
+			if err := flushLiteral(l.position); err != nil {
+				return nil, err
+			}
+			exprStart := l.position + 2
+			if !l.skipInterpolation() {
+				return nil, fmt.Errorf("unterminated ${ in string literal at position %d", position)
+			}
+			parts = append(parts, TemplatePart{
+				Text:         raw[exprStart:l.position],
+				IsExpression: true,
+			})
+			literalStart = l.position + 1
+		}
+		l.readChar()
+	}
+
+	if err := flushLiteral(len(raw)); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+// unescape decodes the escape sequences in the body of a string literal.
+// position is where the literal starts, for error messages.
+func unescape(raw string, position int) (string, error) {
+	var result []byte
+
+	for i := 0; i < len(raw); {
+		ch, size := utf8.DecodeRuneInString(raw[i:])
+		i += size
+		if ch != '\\' {
+			result = utf8.AppendRune(result, ch)
+			continue
+		}
+
+		ch, size = utf8.DecodeRuneInString(raw[i:])
+		i += size
+		switch ch {
+		case '"':
+			result = append(result, '"')
+		case '\'':
+			result = append(result, '\'')
+		case 'n':
+			result = append(result, '\n')
+		case 't':
+			result = append(result, '\t')
+		case 'r':
+			result = append(result, '\r')
+		case '\\':
+			result = append(result, '\\')
+		case '0':
+			result = append(result, 0)
+		case '$':
+			result = append(result, '$')
+		default:
+			return "", fmt.Errorf("unknown escape sequence \\%c in string literal at position %d",
+				ch, position)
+		}
+	}
+	return string(result), nil
+}
@@ -8,6 +8,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if !repl.RunFile(os.Args[1], os.Stdin, os.Stdout) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
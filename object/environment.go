@@ -1,13 +1,21 @@
 package object
 
+import (
+	"fmt"
+	"sort"
+)
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store  map[string]Object
+	consts map[string]bool
+	outer  *Environment
+	state  any
 }
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: nil}
+	c := make(map[string]bool)
+	return &Environment{store: s, consts: c, outer: nil}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
@@ -24,7 +32,98 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
-func (e *Environment) Set(name string, val Object) Object {
+// Declare creates a new binding for name in the current scope, shadowing
+// any binding of the same name in an outer scope. It fails if the current
+// scope already binds name.
+func (e *Environment) Declare(name string, val Object) error {
+	if _, ok := e.store[name]; ok {
+		if e.consts[name] {
+			return fmt.Errorf("cannot reassign constant '%s'", name)
+		}
+		return fmt.Errorf("identifier '%s' has already been declared", name)
+	}
 	e.store[name] = val
-	return val
+	return nil
+}
+
+// Rebind binds name in the current scope like Declare, but replaces a
+// binding the current scope already has instead of failing, unless that
+// binding is a constant.
+func (e *Environment) Rebind(name string, val Object) error {
+	if e.consts[name] {
+		return fmt.Errorf("cannot reassign constant '%s'", name)
+	}
+	e.store[name] = val
+	return nil
+}
+
+// DeclareConst is Declare for a binding that can never be reassigned.
+func (e *Environment) DeclareConst(name string, val Object) error {
+	if err := e.Declare(name, val); err != nil {
+		return err
+	}
+	e.consts[name] = true
+	return nil
+}
+
+// IsConstant reports whether the innermost scope that binds name declared
+// it as a constant.
+func (e *Environment) IsConstant(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.consts[name]
+	}
+	if e.outer != nil {
+		return e.outer.IsConstant(name)
+	}
+	return false
+}
+
+// IsConstantInScope reports whether name is a constant declared in the
+// current scope, ignoring any outer scopes.
+func (e *Environment) IsConstantInScope(name string) bool {
+	return e.consts[name]
+}
+
+// Assign updates name in the innermost scope that already binds it. It
+// fails, leaving every scope untouched, when no scope binds name or the
+// binding is a constant.
+func (e *Environment) Assign(name string, val Object) error {
+	if _, ok := e.store[name]; ok {
+		if e.consts[name] {
+			return fmt.Errorf("cannot reassign constant '%s'", name)
+		}
+		e.store[name] = val
+		return nil
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return fmt.Errorf("cannot assign to undeclared identifier %s, use let to declare it first", name)
+}
+
+// Names returns the names bound in the current scope, ignoring any outer
+// scopes, in sorted order.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetEvalState attaches the evaluator's per-run state, such as how imports
+// are resolved, to e and so to every scope enclosed by it. The object
+// package never looks inside it.
+func (e *Environment) SetEvalState(state any) {
+	e.state = state
+}
+
+// EvalState returns the value given to SetEvalState for e or the nearest
+// enclosing scope, or nil if there is none.
+func (e *Environment) EvalState() any {
+	if e.state != nil || e.outer == nil {
+		return e.state
+	}
+	return e.outer.EvalState()
 }
@@ -0,0 +1,64 @@
+package object
+
+import "testing"
+
+func TestEnvironmentDeclare(t *testing.T) {
+	outer := NewEnvironment()
+	if err := outer.Declare("x", &Integer{Value: 1}); err != nil {
+		t.Fatalf("Declare returned error: %s", err)
+	}
+
+	err := outer.Declare("x", &Integer{Value: 2})
+	if err == nil || err.Error() != "identifier 'x' has already been declared" {
+		t.Errorf("redeclaring x in the same scope gave wrong error. got=%v", err)
+	}
+
+	inner := NewEnclosedEnvironment(outer)
+	if err := inner.Declare("x", &Integer{Value: 3}); err != nil {
+		t.Errorf("shadowing x in an inner scope returned error: %s", err)
+	}
+
+	if val, _ := inner.Get("x"); val.Inspect() != "3" {
+		t.Errorf("inner x is wrong. got=%s", val.Inspect())
+	}
+	if val, _ := outer.Get("x"); val.Inspect() != "1" {
+		t.Errorf("outer x is wrong. got=%s", val.Inspect())
+	}
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Declare("x", &Integer{Value: 1})
+	outer.DeclareConst("PI", &Integer{Value: 3})
+	inner := NewEnclosedEnvironment(outer)
+
+	if err := inner.Assign("x", &Integer{Value: 2}); err != nil {
+		t.Fatalf("Assign returned error: %s", err)
+	}
+	if val, _ := outer.Get("x"); val.Inspect() != "2" {
+		t.Errorf("assigning from an inner scope did not update outer x. got=%s", val.Inspect())
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"PI", "cannot reassign constant 'PI'"},
+		{"y", "cannot assign to undeclared identifier y, use let to declare it first"},
+	}
+
+	for _, tt := range tests {
+		err := inner.Assign(tt.name, &Integer{Value: 0})
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("Assign(%q) gave wrong error. expected=%q, got=%v",
+				tt.name, tt.expected, err)
+		}
+	}
+
+	if _, ok := inner.Get("y"); ok {
+		t.Errorf("failed Assign created a binding for y")
+	}
+	if val, _ := outer.Get("PI"); val.Inspect() != "3" {
+		t.Errorf("failed Assign changed PI. got=%s", val.Inspect())
+	}
+}
@@ -0,0 +1,146 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps Hashable keys to values, remembering the order keys were
+// first set in. Pairs are bucketed by HashKey and a bucket is searched by
+// key, so two keys whose hashes collide are both kept rather than one
+// overwriting the other. A bucket holds indexes into pairs.
+type Hash struct {
+	buckets map[HashKey][]int
+	pairs   []HashPair
+}
+
+func NewHash() *Hash {
+	return &Hash{buckets: make(map[HashKey][]int)}
+}
+
+// find returns the index in h.pairs of the pair stored under key, or -1.
+func (h *Hash) find(hashKey HashKey, key Object) int {
+	for _, i := range h.buckets[hashKey] {
+		if sameKey(h.pairs[i].Key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get returns the value stored under key. It reports false when key is
+// missing or not Hashable.
+func (h *Hash) Get(key Object) (Object, bool) {
+	hashable, ok := AsHashable(key)
+	if !ok {
+		return nil, false
+	}
+
+	if i := h.find(hashable.HashKey(), key); i >= 0 {
+		return h.pairs[i].Value, true
+	}
+	return nil, false
+}
+
+// Set stores value under key, replacing any existing value and keeping
+// the key where it was in the order. It reports false, storing nothing,
+// when key is not Hashable.
+func (h *Hash) Set(key, value Object) bool {
+	hashable, ok := AsHashable(key)
+	if !ok {
+		return false
+	}
+
+	hashKey := hashable.HashKey()
+	if i := h.find(hashKey, key); i >= 0 {
+		h.pairs[i].Value = value
+		return true
+	}
+
+	h.buckets[hashKey] = append(h.buckets[hashKey], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+	return true
+}
+
+// Delete removes key and reports whether it was present. The pairs after
+// it move up, so Delete takes time proportional to the size of the hash.
+func (h *Hash) Delete(key Object) bool {
+	hashable, ok := AsHashable(key)
+	if !ok {
+		return false
+	}
+
+	i := h.find(hashable.HashKey(), key)
+	if i < 0 {
+		return false
+	}
+
+	h.pairs = append(h.pairs[:i], h.pairs[i+1:]...)
+	for hashKey, bucket := range h.buckets {
+		kept := bucket[:0]
+		for _, j := range bucket {
+			switch {
+			case j < i:
+				kept = append(kept, j)
+			case j > i:
+				kept = append(kept, j-1)
+			}
+		}
+		if len(kept) == 0 {
+			delete(h.buckets, hashKey)
+		} else {
+			h.buckets[hashKey] = kept
+		}
+	}
+	return true
+}
+
+func (h *Hash) Len() int { return len(h.pairs) }
+
+// Pairs returns every key-value pair in the hash, in the order their keys
+// were first set.
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, len(h.pairs))
+	copy(pairs, h.pairs)
+	return pairs
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range h.Pairs() {
+		pairs = append(pairs, fmt.Sprintf("%s: %s",
+			pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// sameKey reports whether two keys from the same bucket are equal. Every
+// Hashable type inspects to its value, so comparing the type and the
+// inspected form is enough to tell colliding keys apart. Tuples are
+// compared element by element, since (1,) and ("1",) inspect alike.
+func sameKey(a, b Object) bool {
+	if a, ok := a.(*Tuple); ok {
+		b, ok := b.(*Tuple)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+		for i := range a.Elements {
+			if !sameKey(a.Elements[i], b.Elements[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a.Type() == b.Type() && a.Inspect() == b.Inspect()
+}
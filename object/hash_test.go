@@ -0,0 +1,180 @@
+package object
+
+import "testing"
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+}
+
+func TestHashKeysOfDifferentTypesDoNotCollide(t *testing.T) {
+	integer := &Integer{Value: 1}
+	boolean := &Boolean{Value: true}
+
+	if integer.HashKey().Value != boolean.HashKey().Value {
+		t.Fatalf("expected Integer(1) and Boolean(true) to share a hash value")
+	}
+	if integer.HashKey() == boolean.HashKey() {
+		t.Errorf("Integer(1) and Boolean(true) have the same hash key")
+	}
+
+	hash := NewHash()
+	hash.Set(integer, &String{Value: "integer"})
+	hash.Set(boolean, &String{Value: "boolean"})
+	hash.Set(&String{Value: "1"}, &String{Value: "string"})
+
+	if hash.Len() != 3 {
+		t.Fatalf("hash has wrong length. got=%d", hash.Len())
+	}
+	testHashGet(t, hash, integer, "integer")
+	testHashGet(t, hash, boolean, "boolean")
+	testHashGet(t, hash, &String{Value: "1"}, "string")
+}
+
+// collidingKey hashes every value into the same bucket.
+type collidingKey struct{ name string }
+
+func (c *collidingKey) Type() ObjectType { return STRING_OBJ }
+func (c *collidingKey) Inspect() string  { return c.name }
+func (c *collidingKey) HashKey() HashKey { return HashKey{Type: STRING_OBJ, Value: 42} }
+
+func TestHashKeyCollisions(t *testing.T) {
+	first := &collidingKey{name: "first"}
+	second := &collidingKey{name: "second"}
+
+	hash := NewHash()
+	hash.Set(first, &String{Value: "one"})
+	hash.Set(second, &String{Value: "two"})
+
+	if hash.Len() != 2 {
+		t.Fatalf("colliding keys overwrote each other. len=%d", hash.Len())
+	}
+	testHashGet(t, hash, first, "one")
+	testHashGet(t, hash, &collidingKey{name: "second"}, "two")
+
+	hash.Set(&collidingKey{name: "first"}, &String{Value: "uno"})
+	if hash.Len() != 2 {
+		t.Fatalf("overwriting a colliding key changed the length. len=%d", hash.Len())
+	}
+	testHashGet(t, hash, first, "uno")
+
+	keys := map[string]bool{}
+	for _, pair := range hash.Pairs() {
+		keys[pair.Key.Inspect()] = true
+	}
+	if !keys["first"] || !keys["second"] {
+		t.Errorf("Pairs did not return the original keys. got=%v", keys)
+	}
+
+	if !hash.Delete(first) {
+		t.Fatalf("Delete did not find a colliding key")
+	}
+	if _, ok := hash.Get(first); ok {
+		t.Errorf("deleted key is still present")
+	}
+	testHashGet(t, hash, second, "two")
+
+	if hash.Delete(first) {
+		t.Errorf("Delete reported a missing key as present")
+	}
+	if hash.Len() != 1 {
+		t.Errorf("hash has wrong length after delete. got=%d", hash.Len())
+	}
+}
+
+func TestHashPairsKeepInsertionOrder(t *testing.T) {
+	hash := NewHash()
+	for _, key := range []string{"c", "a", "d", "b"} {
+		hash.Set(&String{Value: key}, &String{Value: key})
+	}
+	hash.Set(&String{Value: "a"}, &String{Value: "again"})
+	hash.Delete(&String{Value: "d"})
+	hash.Set(&String{Value: "d"}, &String{Value: "d"})
+
+	if got := hash.Inspect(); got != "{c: c, a: again, b: b, d: d}" {
+		t.Errorf("pairs are out of order. got=%s", got)
+	}
+	testHashGet(t, hash, &String{Value: "b"}, "b")
+	testHashGet(t, hash, &String{Value: "d"}, "d")
+}
+
+func TestHashUnhashableKeys(t *testing.T) {
+	hash := NewHash()
+	array := &Array{Elements: []Object{}}
+
+	if hash.Set(array, &Integer{Value: 1}) {
+		t.Errorf("Set accepted an unhashable key")
+	}
+	if _, ok := hash.Get(array); ok {
+		t.Errorf("Get found an unhashable key")
+	}
+	if hash.Delete(array) {
+		t.Errorf("Delete removed an unhashable key")
+	}
+	if hash.Len() != 0 {
+		t.Errorf("hash has wrong length. got=%d", hash.Len())
+	}
+}
+
+func testHashGet(t *testing.T, hash *Hash, key Object, expected string) {
+	t.Helper()
+
+	value, ok := hash.Get(key)
+	if !ok {
+		t.Errorf("hash has no key %s", key.Inspect())
+		return
+	}
+	if value.Inspect() != expected {
+		t.Errorf("wrong value for key %s. expected=%q, got=%q",
+			key.Inspect(), expected, value.Inspect())
+	}
+}
+
+func TestTupleHashKeys(t *testing.T) {
+	intTuple := &Tuple{Elements: []Object{&Integer{Value: 1}}}
+	strTuple := &Tuple{Elements: []Object{&String{Value: "1"}}}
+	pair := &Tuple{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+	samePair := &Tuple{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}}
+
+	if pair.HashKey() != samePair.HashKey() {
+		t.Errorf("tuples with same elements have different hash keys")
+	}
+
+	hash := NewHash()
+	hash.Set(intTuple, &String{Value: "int"})
+	hash.Set(strTuple, &String{Value: "string"})
+	hash.Set(pair, &String{Value: "pair"})
+	hash.Set(samePair, &String{Value: "same pair"})
+
+	if hash.Len() != 3 {
+		t.Fatalf("hash has wrong number of pairs. got=%d, want=3", hash.Len())
+	}
+	if value, _ := hash.Get(intTuple); value.Inspect() != "int" {
+		t.Errorf("(1,) maps to %s, want int", value.Inspect())
+	}
+	if value, _ := hash.Get(pair); value.Inspect() != "same pair" {
+		t.Errorf("(1, a) maps to %s, want same pair", value.Inspect())
+	}
+
+	unhashable := &Tuple{Elements: []Object{&Integer{Value: 1}, &Array{}}}
+	if _, ok := AsHashable(unhashable); ok {
+		t.Errorf("tuple holding an array should not be hashable")
+	}
+	if hash.Set(unhashable, &Null{}) {
+		t.Errorf("Set accepted a tuple holding an array")
+	}
+}
@@ -4,21 +4,29 @@ import (
 	"bananaScript/ast"
 	"bytes"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
 )
 
 type ObjectType string
 
 const (
-	INTEGER_OBJ      = "INTEGER"
-	STRING_OBJ       = "STRING"
-	BOOLEAN_OBJ      = "BOOLEAN"
-	NULL_OBJ         = "NULL"
-	RETURN_VALUE_OBJ = "RETURN_VALUE"
-	ERROR_OBJ        = "ERROR"
-	FUNCTION_OBJ     = "FUNCTION"
-	BUILTIN_OBJ      = "BUILTIN"
-	ARRAY_OBJ        = "ARRAY"
+	INTEGER_OBJ        = "INTEGER"
+	FLOAT_OBJ          = "FLOAT"
+	STRING_OBJ         = "STRING"
+	BOOLEAN_OBJ        = "BOOLEAN"
+	NULL_OBJ           = "NULL"
+	RETURN_VALUE_OBJ   = "RETURN_VALUE"
+	BREAK_VALUE_OBJ    = "BREAK_VALUE"
+	CONTINUE_VALUE_OBJ = "CONTINUE_VALUE"
+	ERROR_OBJ          = "ERROR"
+	FUNCTION_OBJ       = "FUNCTION"
+	BUILTIN_OBJ        = "BUILTIN"
+	ARRAY_OBJ          = "ARRAY"
+	HASH_OBJ           = "HASH"
+	SET_OBJ            = "SET"
+	TUPLE_OBJ          = "TUPLE"
 )
 
 type Object interface {
@@ -33,6 +41,15 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// Float deliberately does not implement Hashable: floating-point equality
+// makes for unreliable hash keys.
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Inspect() string  { return strconv.FormatFloat(f.Value, 'f', -1, 64) }
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type String struct {
 	Value string
 }
@@ -47,6 +64,33 @@ type Boolean struct {
 func (i *Boolean) Inspect() string  { return fmt.Sprintf("%t", i.Value) }
 func (i *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
 type Null struct{}
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
@@ -59,26 +103,60 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+type BreakValue struct{}
+
+func (bv *BreakValue) Type() ObjectType { return BREAK_VALUE_OBJ }
+func (bv *BreakValue) Inspect() string  { return "break" }
+
+type ContinueValue struct{}
+
+func (cv *ContinueValue) Type() ObjectType { return CONTINUE_VALUE_OBJ }
+func (cv *ContinueValue) Inspect() string  { return "continue" }
+
+// Error is a runtime error. A Fatal error comes from a limit on the run
+// itself, such as its deadline or the call depth, and try cannot catch it.
 type Error struct {
 	Message string
+	Fatal   bool
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
+// Function is a user-defined function. Defaults holds each parameter's
+// default value, or nil, and is evaluated in Env when an argument is
+// missing or null. When Variadic is set, the last parameter collects any
+// extra arguments into an array.
 type Function struct {
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression
+	Variadic   bool
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
 
+// Default returns the default value of the i-th parameter, or nil.
+func (f *Function) Default(i int) ast.Expression {
+	if i < len(f.Defaults) {
+		return f.Defaults[i]
+	}
+	return nil
+}
+
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
 	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
+	for i, p := range f.Parameters {
+		if def := f.Default(i); def != nil {
+			params = append(params, p.String()+" = "+def.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Variadic {
+		params[len(params)-1] = "..." + params[len(params)-1]
 	}
 
 	out.WriteString("fn")
@@ -93,8 +171,12 @@ func (f *Function) Inspect() string {
 
 type BuiltinFunction func(args ...Object) Object
 
+// Builtin is a function provided by the interpreter. A builtin that
+// depends on the run it is called from, such as where print writes, sets
+// EnvFn instead of Fn to be given the environment of the call.
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn    BuiltinFunction
+	EnvFn func(env *Environment, args ...Object) Object
 }
 
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
@@ -0,0 +1,73 @@
+package object
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Set holds unique Hashable values. It keeps them as the keys of a Hash,
+// so values whose hash keys collide stay separate elements.
+type Set struct {
+	elements *Hash
+}
+
+func NewSet() *Set {
+	return &Set{elements: NewHash()}
+}
+
+// Add inserts value and reports false, leaving the set unchanged, when
+// value is not Hashable.
+func (s *Set) Add(value Object) bool {
+	return s.elements.Set(value, value)
+}
+
+// Has reports whether value is in the set.
+func (s *Set) Has(value Object) bool {
+	_, ok := s.elements.Get(value)
+	return ok
+}
+
+// Remove deletes value and reports whether it was in the set.
+func (s *Set) Remove(value Object) bool {
+	return s.elements.Delete(value)
+}
+
+func (s *Set) Len() int { return s.elements.Len() }
+
+// Elements returns every value in the set, in the order they were added.
+func (s *Set) Elements() []Object {
+	elements := make([]Object, 0, s.elements.Len())
+	for _, pair := range s.elements.Pairs() {
+		elements = append(elements, pair.Key)
+	}
+	return elements
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+
+// Inspect renders the set as `{1, 2, 3}`, sorting elements so the output
+// is stable: by type first, integers numerically and the rest by text.
+func (s *Set) Inspect() string {
+	elements := s.Elements()
+	sort.Slice(elements, func(i, j int) bool {
+		a, b := elements[i], elements[j]
+		if a.Type() != b.Type() {
+			return a.Type() < b.Type()
+		}
+		if x, ok := a.(*Integer); ok {
+			return x.Value < b.(*Integer).Value
+		}
+		return a.Inspect() < b.Inspect()
+	})
+
+	var out bytes.Buffer
+	items := make([]string, len(elements))
+	for i, el := range elements {
+		items[i] = el.Inspect()
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(items, ", "))
+	out.WriteString("}")
+	return out.String()
+}
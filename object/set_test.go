@@ -0,0 +1,56 @@
+package object
+
+import "testing"
+
+func TestSetKeyCollisions(t *testing.T) {
+	first := &collidingKey{name: "first"}
+	second := &collidingKey{name: "second"}
+
+	set := NewSet()
+	set.Add(first)
+	set.Add(second)
+
+	if set.Len() != 2 {
+		t.Fatalf("colliding elements merged into one. len=%d", set.Len())
+	}
+	if !set.Has(&collidingKey{name: "first"}) || !set.Has(second) {
+		t.Errorf("set lost a colliding element. got=%s", set.Inspect())
+	}
+	if set.Has(&collidingKey{name: "third"}) {
+		t.Errorf("set has an element that shares only a hash key")
+	}
+
+	set.Add(&collidingKey{name: "first"})
+	if set.Len() != 2 {
+		t.Errorf("adding an element again changed the length. len=%d", set.Len())
+	}
+
+	if !set.Remove(first) {
+		t.Fatalf("Remove did not find a colliding element")
+	}
+	if set.Has(first) || !set.Has(second) {
+		t.Errorf("Remove took out the wrong element. got=%s", set.Inspect())
+	}
+}
+
+func TestSetElementsInOrder(t *testing.T) {
+	set := NewSet()
+	set.Add(&Integer{Value: 1})
+	set.Add(&Boolean{Value: true})
+	set.Add(&String{Value: "1"})
+
+	if set.Len() != 3 {
+		t.Fatalf("set has wrong length. got=%d", set.Len())
+	}
+	elements := set.Elements()
+	types := []ObjectType{INTEGER_OBJ, BOOLEAN_OBJ, STRING_OBJ}
+	for i, el := range elements {
+		if el.Type() != types[i] {
+			t.Errorf("elements are not in the order they were added. got=%v", elements)
+			break
+		}
+	}
+	if set.Add(&Array{}) {
+		t.Errorf("Add accepted an unhashable value")
+	}
+}
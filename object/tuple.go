@@ -0,0 +1,65 @@
+package object
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"strings"
+)
+
+// Tuple is an immutable, fixed-length sequence. Nothing may change its
+// elements once it is built, so a tuple of Hashable elements is itself
+// usable as a hash key.
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, e := range t.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	out.WriteString("(")
+	out.WriteString(strings.Join(elements, ", "))
+	if len(elements) == 1 {
+		out.WriteString(",")
+	}
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// HashKey combines the hash keys of the elements. It must only be called
+// on a tuple that AsHashable accepts.
+func (t *Tuple) HashKey() HashKey {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, el := range t.Elements {
+		key := el.(Hashable).HashKey()
+		h.Write([]byte(key.Type))
+		binary.LittleEndian.PutUint64(buf[:], key.Value)
+		h.Write(buf[:])
+	}
+	return HashKey{Type: t.Type(), Value: h.Sum64()}
+}
+
+// AsHashable returns obj as a Hashable, reporting false for values that
+// cannot be hash keys. Use it rather than a plain type assertion, since a
+// tuple is only hashable when all of its elements are.
+func AsHashable(obj Object) (Hashable, bool) {
+	if tuple, ok := obj.(*Tuple); ok {
+		for _, el := range tuple.Elements {
+			if _, ok := AsHashable(el); !ok {
+				return nil, false
+			}
+		}
+		return tuple, true
+	}
+
+	hashable, ok := obj.(Hashable)
+	return hashable, ok
+}
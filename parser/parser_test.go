@@ -4,6 +4,7 @@ import (
 	"bananaScript/ast"
 	"bananaScript/lexer"
 	"fmt"
+	"slices"
 	"testing"
 )
 
@@ -41,6 +42,35 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestConstStatements(t *testing.T) {
+	input := "const PI = 3;"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("s not *ast.LetStatement. got=%T", program.Statements[0])
+	}
+	if stmt.TokenLiteral() != "const" {
+		t.Errorf("s.TokenLiteral not 'const'. got=%q", stmt.TokenLiteral())
+	}
+	if stmt.Name.Value != "PI" {
+		t.Errorf("stmt.Name.Value not 'PI'. got=%s", stmt.Name.Value)
+	}
+	testLiteralExpression(t, stmt.Value, 3)
+
+	if program.String() != "const PI = 3;" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
 		t.Errorf("s.TokenLiteral not 'let'. got=%q", s.TokenLiteral())
@@ -162,6 +192,169 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestNullLiteralExpression(t *testing.T) {
+	input := "null;"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program has not enough statements. got=%d",
+			len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+	literal, ok := stmt.Expression.(*ast.NullLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.NullLiteral. got=%T", stmt.Expression)
+	}
+	if literal.TokenLiteral() != "null" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "null",
+			literal.TokenLiteral())
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"0.5", 0.5},
+		{"10.0", 10},
+		{"1_000.25", 1000.25},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+		if literal.TokenLiteral() != tt.input {
+			t.Errorf("literal.TokenLiteral not %s. got=%s", tt.input,
+				literal.TokenLiteral())
+		}
+	}
+
+	l := lexer.New("1._5")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected parser errors for 1._5, got none")
+	}
+
+	l = lexer.New("1_.5")
+	p = New(l)
+	p.ParseProgram()
+	expected := `invalid digit separator in "1_.5": underscores must sit between digits`
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%v", expected, p.Errors())
+	}
+}
+
+func TestRadixIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0o17", 15},
+		{"0O17", 15},
+		{"0b101", 5},
+		{"0B11", 3},
+		{"0x7FFFFFFFFFFFFFFF", 9223372036854775807},
+		{"1_000_000", 1000000},
+		{"0xFF_FF", 65535},
+		{"0b1010_1010", 170},
+		{"0o7_7", 63},
+		{"0", 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+		if literal.TokenLiteral() != tt.input {
+			t.Errorf("literal.TokenLiteral not %s. got=%s", tt.input,
+				literal.TokenLiteral())
+		}
+	}
+}
+
+func TestMalformedIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0x", `illegal token: hexadecimal literal "0x" has no digits`},
+		{"0b_", `illegal token: binary literal "0b_" has no digits`},
+		{"0b102", `illegal token: invalid digit '2' in binary literal "0b102"`},
+		{"0o8", `illegal token: invalid digit '8' in octal literal "0o8"`},
+		{"0x1G", `illegal token: invalid digit 'G' in hexadecimal literal "0x1G"`},
+		{"0x8000000000000000", `could not parse "0x8000000000000000" as integer`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input + ";")
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q, got none", tt.input)
+			continue
+		}
+
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestMisplacedDigitSeparators(t *testing.T) {
+	tests := []string{"1__0", "_5", "10_", "0x_FF", "0b1_", "1_000__000"}
+
+	for _, input := range tests {
+		l := lexer.New(input + ";")
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+			continue
+		}
+
+		expected := fmt.Sprintf("invalid digit separator in %q: underscores must sit between digits", input)
+		if errors[0] != expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", expected, errors[0])
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input        string
@@ -202,6 +395,41 @@ func TestParsingPrefixExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingPostfixExpressions(t *testing.T) {
+	postfixTests := []struct {
+		input    string
+		operator string
+	}{
+		{"x++;", "++"},
+		{"x--;", "--"},
+	}
+	for _, tt := range postfixTests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		exp, ok := stmt.Expression.(*ast.PostfixExpression)
+		if !ok {
+			t.Fatalf("stmt is not ast.PostfixExpression. got=%T", stmt.Expression)
+		}
+		if exp.Operator != tt.operator {
+			t.Fatalf("exp.Operator is not '%s'. got=%s",
+				tt.operator, exp.Operator)
+		}
+		if !testIdentifier(t, exp.Left, "x") {
+			return
+		}
+	}
+}
+
 func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
 	integ, ok := il.(*ast.IntegerLiteral)
 	if !ok {
@@ -235,9 +463,18 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 / 5;", 5, "/", 5},
 		{"5 > 5;", 5, ">", 5},
 		{"5 < 5;", 5, "<", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 <= 5;", 5, "<=", 5},
 		{"5 == 5;", 5,
 			"==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 & 5;", 5, "&", 5},
+		{"5 | 5;", 5, "|", 5},
+		{"5 ^ 5;", 5, "^", 5},
+		{"5 xor 5;", 5, "xor", 5},
+		{"5 in 5;", 5, "in", 5},
+		{"5 << 5;", 5, "<<", 5},
+		{"5 >> 5;", 5, ">>", 5},
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
@@ -325,6 +562,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"5 < 4 != 3 > 4",
 			"((5 < 4) != (3 > 4))",
 		},
+		{
+			"5 >= 4 == 3 <= 4",
+			"((5 >= 4) == (3 <= 4))",
+		},
+		{
+			"a + b <= c * d",
+			"((a + b) <= (c * d))",
+		},
 		{
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
@@ -360,6 +605,37 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"(2 / (5 + 5))",
 		},
 		{"-(5 + 5)", "(-(5 + 5))"},
+		{"a == b ? c + d : -e", "((a == b) ? (c + d) : (-e))"},
+		{"x = a ? b : c", "x = (a ? b : c)"},
+		{"a ? b : c ? d : e", "(a ? b : (c ? d : e))"},
+		{"a ? b ? c : d : e", "(a ? (b ? c : d) : e)"},
+		{"a ? b : c ? d : e ? f : g", "(a ? b : (c ? d : (e ? f : g)))"},
+		{"a ?? b == c", "(a ?? (b == c))"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		{"a ?? b ? c : d", "((a ?? b) ? c : d)"},
+		{"x = a ?? 1 + 2", "x = (a ?? (1 + 2))"},
+		{"++a", "(++a)"},
+		{"a--", "(a--)"},
+		{"-a++", "(-(a++))"},
+		{"a++ + --b", "((a++) + (--b))"},
+		{"~a & b", "((~a) & b)"},
+		{"a | b xor c & d", "(a | (b xor (c & d)))"},
+		{"a ^ b ^ c", "(a ^ (b ^ c))"},
+		{"a * b ^ c", "(a * (b ^ c))"},
+		{"a ^ b * c", "((a ^ b) * c)"},
+		{"-a ^ b", "(-(a ^ b))"},
+		{"a ^ -b", "(a ^ (-b))"},
+		{"a - b ^ c - d", "((a - (b ^ c)) - d)"},
+		{"a++ ^ b", "((a++) ^ b)"},
+		{"a & b << c", "(a & (b << c))"},
+		{"a << b + c", "(a << (b + c))"},
+		{"a < b | c", "(a < (b | c))"},
+		{"a == b & c", "(a == (b & c))"},
+		{"~a xor b", "((~a) xor b)"},
+		{"a + b in c", "((a + b) in c)"},
+		{"a in b == true", "((a in b) == true)"},
+		{"!(a in b)", "(!(a in b))"},
+		{"a >> b - c", "(a >> (b - c))"},
 		{"!(true == true)", "(!(true == true))"},
 		{
 			"a + add(b * c) + d",
@@ -579,8 +855,8 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
-func TestFunctionLiteralParsing(t *testing.T) {
-	input := `fn(x, y) { x + y; }`
+func TestTernaryExpression(t *testing.T) {
+	input := `x < y ? x : y`
 	l := lexer.New(input)
 	p := New(l)
 	program := p.ParseProgram()
@@ -596,128 +872,747 @@ func TestFunctionLiteralParsing(t *testing.T) {
 			program.Statements[0])
 	}
 
-	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	exp, ok := stmt.Expression.(*ast.TernaryExpression)
 	if !ok {
-		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T",
+		t.Fatalf("stmt.Expression is not ast.TernaryExpression. got=%T",
 			stmt.Expression)
 	}
 
-	if len(function.Parameters) != 2 {
-		t.Fatalf("function literal parameters wrong. want 2, got=%d\n", len(function.Parameters))
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
 	}
-
-	testLiteralExpression(t, function.Parameters[0], "x")
-	testLiteralExpression(t, function.Parameters[1], "y")
-
-	if len(function.Body.Statements) != 1 {
-		t.Fatalf("function.Body.Statements has not 1 statements. got=%d\n", len(function.Body.Statements))
+	if !testIdentifier(t, exp.Consequence, "x") {
+		return
 	}
-
-	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T",
-			function.Body.Statements[0])
+	if !testIdentifier(t, exp.Alternative, "y") {
+		return
 	}
-	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
 }
 
-func TestFunctionParameterParsing(t *testing.T) {
+func TestTernaryMissingColon(t *testing.T) {
 	tests := []struct {
-		input          string
-		expectedParams []string
+		input    string
+		expected string
 	}{
-		{input: "fn() {};", expectedParams: []string{}},
-		{input: "fn(x) {};", expectedParams: []string{"x"}},
-		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+		{"a ? b", "missing : in ternary expression a ? b, got EOF instead"},
+		{"let x = score > 50 ? \"pass\";", "missing : in ternary expression (score > 50) ? pass, got ; instead"},
 	}
+
 	for _, tt := range tests {
-		l := lexer.New(tt.input)
-		p := New(l)
-		program := p.ParseProgram()
-		checkParserErrors(t, p)
-		stmt := program.Statements[0].(*ast.ExpressionStatement)
-		function := stmt.Expression.(*ast.FunctionLiteral)
-		if len(function.Parameters) != len(tt.expectedParams) {
-			t.Errorf("length parameters wrong. want %d, got=%d\n", len(tt.expectedParams), len(function.Parameters))
-			for i, ident := range tt.expectedParams {
-				testLiteralExpression(t, function.Parameters[i], ident)
-			}
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong errors for %s. expected=%q, got=%q", tt.input, tt.expected, errors)
 		}
 	}
 }
 
-func TestCallExpressionParsing(t *testing.T) {
-	input := "add(1, 2 * 3, 4 + 5);"
-	l := lexer.New(input)
-	p := New(l)
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) { 1 => "one", 2 + 0 => "two", _ => "many", }`
+
+	p := New(lexer.New(input))
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
 
 	if len(program.Statements) != 1 {
-		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+			len(program.Statements))
 	}
-
-	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
 	if !ok {
-		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T",
-			program.Statements[0])
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
 	}
 
-	exp, ok := stmt.Expression.(*ast.CallExpression)
-	if !ok {
-		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T",
-			stmt.Expression)
+	testIdentifier(t, exp.Subject, "x")
+	if len(exp.Arms) != 3 {
+		t.Fatalf("match does not have 3 arms. got=%d", len(exp.Arms))
+	}
+	testIntegerLiteral(t, exp.Arms[0].Pattern, 1)
+	testInfixExpression(t, exp.Arms[1].Pattern, 2, "+", 0)
+	if exp.Arms[2].Pattern != nil {
+		t.Errorf("_ arm has a pattern. got=%s", exp.Arms[2].Pattern)
+	}
+	if exp.String() != "match (x) { 1 => one, (2 + 0) => two, _ => many }" {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
+	}
+	if len(p.Warnings()) != 0 {
+		t.Errorf("unexpected warnings. got=%q", p.Warnings())
 	}
+}
 
-	if !testIdentifier(t, exp.Function, "add") {
-		return
+func TestMatchExpressionWarnings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`match (x) { 1 => "a", 1 => "b" }`, []string{"duplicate match arm 1 => b"}},
+		{`match (x) { "1" => "a", 1 => "b" }`, nil},
+		{`match (x) { _ => "a", 1 => "b" }`, []string{"unreachable match arm 1 => b after _"}},
+		{`match (x) { y => "a", y => "b" }`, nil},
 	}
 
-	if len(exp.Arguments) != 3 {
-		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		checkParserErrors(t, p)
+
+		warnings := p.Warnings()
+		if len(warnings) != len(tt.expected) {
+			t.Errorf("wrong warnings for %s. expected=%q, got=%q", tt.input, tt.expected, warnings)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if warnings[i] != msg {
+				t.Errorf("wrong warning for %s. expected=%q, got=%q", tt.input, msg, warnings[i])
+			}
+		}
 	}
 
-	testLiteralExpression(t, exp.Arguments[0], 1)
-	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
-	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+	p := New(lexer.New(`match (x) { 1 "one" }`))
+	p.ParseProgram()
+	errors := p.Errors()
+	if len(errors) == 0 || errors[0] != "expected next token to be =>, got STRING instead" {
+		t.Errorf("arm without => gave wrong errors. got=%q", errors)
+	}
 }
 
-func TestStringLiteralExpression(t *testing.T) {
-	input := `"hello world";`
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`try { f() } catch (e) { 0 }`, "try f() catch (e) 0"},
+		{`try { f() } finally { g() }`, "try f() finally g()"},
+		{`try { f() } catch (err) { 0 } finally { g() }`, "try f() catch (err) 0 finally g()"},
+		{`throw "boom";`, "throw boom;"},
+		{`import "lib/math"`, `import "lib/math";`},
+		{`let m = import("math.bs"); m["square"](4)`, `let m = import(math.bs);(m[square])(4)`},
+		{`import("lib/" + name)`, `import((lib/ + name))`},
+	}
 
-	l := lexer.New(input)
-	p := New(l)
-	program := p.ParseProgram()
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
 
-	checkParserErrors(t, p)
-	stmt := program.Statements[0].(*ast.ExpressionStatement)
-	literal, ok := stmt.Expression.(*ast.StringLiteral)
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
 
+	program := New(lexer.New(`try { x } catch (e) { y }`)).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.TryExpression)
 	if !ok {
-		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+		t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T", stmt.Expression)
+	}
+	testIdentifier(t, exp.Param, "e")
+	if exp.Finally != nil {
+		t.Errorf("exp.Finally is not nil. got=%s", exp.Finally)
 	}
 
-	if literal.Value != "hello world" {
-		t.Errorf("literal.Value not %q. got=%q", "hello world", literal.Value)
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`try { x }`, "try without catch or finally"},
+		{`try { x } catch { y }`, "expected next token to be (, got { instead"},
+		{`import math`, "expected next token to be (, got IDENT instead"},
+		{`import("math"`, "expected next token to be ), got EOF instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("%s gave wrong errors. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
 	}
 }
 
-func TestParsingArrayLiterals(t *testing.T) {
-	input := "[1, 2 * 2, 3 + 3]"
-
+func TestIfElseIfExpression(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else { z }`
 	l := lexer.New(input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
 
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
 	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-	array, ok := stmt.Expression.(*ast.ArrayLiteral)
 	if !ok {
-		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
 	}
 
-	if len(array.Elements) != 3 {
-		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	elseIf := exp.ElseIf()
+	if elseIf == nil {
+		t.Fatalf("exp.ElseIf() is nil. alternative=%+v", exp.Alternative)
+	}
+
+	if !testInfixExpression(t, elseIf.Condition, "x", ">", "y") {
+		return
+	}
+
+	if elseIf.Alternative == nil || elseIf.ElseIf() != nil {
+		t.Fatalf("final else was not parsed as a block. got=%+v", elseIf.Alternative)
+	}
+
+	alternative, ok := elseIf.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
+			elseIf.Alternative.Statements[0])
+	}
+
+	if !testIdentifier(t, alternative.Expression, "z") {
+		return
+	}
+
+	expected := "if (x < y) { x } else if (x > y) { y } else { z }"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestElseBlockContainingIf(t *testing.T) {
+	input := `if (a) { 1 } else { if (b) { 2 } }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if exp.ElseIf() != nil {
+		t.Errorf("a plain else block was reported as else-if")
+	}
+
+	expected := "if a { 1 } else { if b { 2 } }"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	input := `for (let i = 0; i < 10; i++) { x }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testLetStatement(t, stmt.Init, "i") {
+		return
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "i", "<", 10) {
+		return
+	}
+
+	post, ok := stmt.Post.(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt.Post is not ast.ExpressionStatement. got=%T", stmt.Post)
+	}
+	if post.String() != "(i++)" {
+		t.Errorf("post.String() wrong. got=%q", post.String())
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Errorf("body is not 1 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+
+	if program.String() != "for (let i = 0; (i < 10); (i++)) x" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestForStatementEmptyClauses(t *testing.T) {
+	input := `for (;;) { break; continue; }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if stmt.Init != nil || stmt.Condition != nil || stmt.Post != nil {
+		t.Errorf("expected empty clauses. got=%q", stmt.String())
+	}
+
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("body is not 2 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+	if _, ok := stmt.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[0] is not ast.BreakStatement. got=%T", stmt.Body.Statements[0])
+	}
+	if _, ok := stmt.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[1] is not ast.ContinueStatement. got=%T", stmt.Body.Statements[1])
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < y) { x++; }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Errorf("body is not 1 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+
+	if program.String() != "while (x < y) (x++)" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	input := `do { x++; } while (x < y);`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.DoWhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.DoWhileStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Errorf("body is not 1 statements. got=%d\n", len(stmt.Body.Statements))
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if program.String() != "do (x++) while (x < y);" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestDoWhileStatementMissingWhile(t *testing.T) {
+	l := lexer.New(`do { x++; } (x < y);`)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	expected := "expected next token to be WHILE, got ( instead"
+	if errors[0] != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errors[0])
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T",
+			stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d\n", len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0], "x")
+	testLiteralExpression(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statements. got=%d\n", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T",
+			function.Body.Statements[0])
+	}
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{input: "fn() {};", expectedParams: []string{}},
+		{input: "fn(x) {};", expectedParams: []string{"x"}},
+		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+	}
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d\n", len(tt.expectedParams), len(function.Parameters))
+			for i, ident := range tt.expectedParams {
+				testLiteralExpression(t, function.Parameters[i], ident)
+			}
+		}
+	}
+}
+
+func TestVariadicParameterParsing(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedParams   []string
+		expectedVariadic bool
+		expectedString   string
+	}{
+		{"fn(...rest) { rest }", []string{"rest"}, true, "fn(...rest) rest"},
+		{"fn(a, b, ...rest) { rest }", []string{"a", "b", "rest"}, true, "fn(a, b, ...rest) rest"},
+		{"fn(a, b) { a }", []string{"a", "b"}, false, "fn(a, b) a"},
+		{"fn(a, b,) { a }", []string{"a", "b"}, false, "fn(a, b) a"},
+		{"fn(a, ...rest,) { rest }", []string{"a", "rest"}, true, "fn(a, ...rest) rest"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FunctionLiteral. got=%T", stmt.Expression)
+		}
+		if function.Variadic != tt.expectedVariadic {
+			t.Errorf("function.Variadic wrong for %s. want=%t, got=%t",
+				tt.input, tt.expectedVariadic, function.Variadic)
+		}
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("length parameters wrong. want %d, got=%d",
+				len(tt.expectedParams), len(function.Parameters))
+		}
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+		if function.String() != tt.expectedString {
+			t.Errorf("function.String() wrong. want=%q, got=%q",
+				tt.expectedString, function.String())
+		}
+	}
+
+	for _, input := range []string{"fn(a, ...rest, b) { a }", "fn f(...rest, b) { b }"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != "rest parameter ...rest must be the last parameter" {
+			t.Errorf("rest parameter before another parameter in %s gave wrong errors. got=%q", input, errors)
+		}
+	}
+}
+
+func TestFunctionStatementParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fn greet(name, greeting = "hello") { greeting }`, "let greet = fn(name, greeting = hello) greeting;"},
+		{`fn add(x, y) { x + y }; add(1, 2)`, "let add = fn(x, y) (x + y);add(1, 2)"},
+		{`fn(x) { x }(1)`, "fn(x) x(1)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestDefaultParameterParsing(t *testing.T) {
+	input := `fn(x, y = 10, z = "hello", ...rest) { x }`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+	if len(function.Parameters) != 4 || len(function.Defaults) != 4 {
+		t.Fatalf("wrong number of parameters or defaults. got=%d, %d",
+			len(function.Parameters), len(function.Defaults))
+	}
+	if function.Defaults[0] != nil || function.Defaults[3] != nil {
+		t.Errorf("x and rest should have no defaults. got=%v, %v",
+			function.Defaults[0], function.Defaults[3])
+	}
+	testIntegerLiteral(t, function.Defaults[1], 10)
+	if lit, ok := function.Defaults[2].(*ast.StringLiteral); !ok || lit.Value != "hello" {
+		t.Errorf("default of z is not %q. got=%v", "hello", function.Defaults[2])
+	}
+	if function.String() != "fn(x, y = 10, z = hello, ...rest) x" {
+		t.Errorf("function.String() wrong. got=%q", function.String())
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(a = 1, b) { a }", "parameter b without a default cannot follow one with a default"},
+		{"fn(a, ...rest = [1]) { a }", "rest parameter ...rest cannot have a default value"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong errors for %s. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestSpreadArgumentParsing(t *testing.T) {
+	p := New(lexer.New("add(1, ...rest, ...[2, 3])"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.CallExpression. got=%T", stmt.Expression)
+	}
+	if len(call.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(call.Arguments))
+	}
+
+	testLiteralExpression(t, call.Arguments[0], 1)
+	spread, ok := call.Arguments[1].(*ast.SpreadExpression)
+	if !ok {
+		t.Fatalf("call.Arguments[1] not *ast.SpreadExpression. got=%T", call.Arguments[1])
+	}
+	testIdentifier(t, spread.Value, "rest")
+	if call.String() != "add(1, ...rest, ...[2, 3])" {
+		t.Errorf("call.String() wrong. got=%q", call.String())
+	}
+
+	p = New(lexer.New("[1, ...rest, 9]"))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt = program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+	if _, ok := array.Elements[1].(*ast.SpreadExpression); !ok {
+		t.Fatalf("array.Elements[1] not *ast.SpreadExpression. got=%T", array.Elements[1])
+	}
+	if array.String() != "[1, ...rest, 9]" {
+		t.Errorf("array.String() wrong. got=%q", array.String())
+	}
+
+	for _, input := range []string{"let a = ...rest", "{1: ...rest}", "(...rest)"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != "no prefix parse function for ... found" {
+			t.Errorf("spread in %s gave wrong errors. got=%q", input, errors)
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("stmt is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Function, "add") {
+		return
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello world";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.Value != "hello world" {
+		t.Errorf("literal.Value not %q. got=%q", "hello world", literal.Value)
+	}
+}
+
+func TestInterpolatedStringExpression(t *testing.T) {
+	input := `"sum is ${a + b}!";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	if len(str.Parts) != 3 {
+		t.Fatalf("str.Parts does not contain 3 parts. got=%d", len(str.Parts))
+	}
+	if lit, ok := str.Parts[0].(*ast.StringLiteral); !ok || lit.Value != "sum is " {
+		t.Errorf("str.Parts[0] is not %q. got=%s", "sum is ", str.Parts[0])
+	}
+	testInfixExpression(t, str.Parts[1], "a", "+", "b")
+	if lit, ok := str.Parts[2].(*ast.StringLiteral); !ok || lit.Value != "!" {
+		t.Errorf("str.Parts[2] is not %q. got=%s", "!", str.Parts[2])
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"total ${a +}"`, `in interpolation of string "total ${a +}": no prefix parse function for EOF found`},
+		{`"${}"`, `empty interpolation in string "${}"`},
+		{`"${a b}"`, `in interpolation of string "${a b}": unexpected IDENT after expression`},
+		{`"sum ${1"`, "illegal token: unterminated ${ in string literal at position 1"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %s, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error for %s. expected=%q, got=%q",
+				tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
 	}
 
 	testIntegerLiteral(t, array.Elements[0], 1)
@@ -725,6 +1620,157 @@ func TestParsingArrayLiterals(t *testing.T) {
 	testInfixExpression(t, array.Elements[2], 3, "+", 3)
 }
 
+func TestTrailingCommas(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1,]", "[1]"},
+		{"[1, 2,]", "[1, 2]"},
+		{"[\n  1,\n  2,\n]", "[1, 2]"},
+		{"[[1,], [2, 3,],]", "[[1], [2, 3]]"},
+		{"[...xs,]", "[...xs]"},
+		{"add(1,)", "add(1)"},
+		{"add(1, 2,)", "add(1, 2)"},
+		{"f([1,], {\"a\": [2,],},)", "f([1], {a:[2]})"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"[,]", "f(,)", "[1,,]"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != "no prefix parse function for , found" {
+			t.Errorf("wrong errors for %s. got=%q", input, errors)
+		}
+	}
+}
+
+func TestParsingTupleLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"()", "()"},
+		{"(1,)", "(1,)"},
+		{"(1, 2 * 2, 3 + 3)", "(1, (2 * 2), (3 + 3))"},
+		{"(1, 2,)", "(1, 2)"},
+		{"(1)", "1"},
+		{"((1, 2), (3,))[0]", "(((1, 2), (3,))[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("(1, 2 * 2)")).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	tuple, ok := stmt.Expression.(*ast.TupleLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.TupleLiteral. got=%T", stmt.Expression)
+	}
+	if len(tuple.Elements) != 2 {
+		t.Fatalf("len(tuple.Elements) not 2. got=%d", len(tuple.Elements))
+	}
+	testIntegerLiteral(t, tuple.Elements[0], 1)
+	testInfixExpression(t, tuple.Elements[1], 2, "*", 2)
+}
+
+func TestMultipleReturnAndDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"return 1, 2 * 2;", "return (1, (2 * 2));"},
+		{"return (1, 2);", "return (1, 2);"},
+		{"let a, b = f();", "let a, b = f();"},
+		{"let a, b = b, a;", "let a, b = (b, a);"},
+		{"const x, y, z = t", "const x, y, z = t;"},
+		{"let [a, b] = pair;", "let [a, b] = pair;"},
+		{"let [head, ...tail] = xs", "let [head, ...tail] = xs;"},
+		{"const [only] = [1]", "const [only] = [1];"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("let a, b = f();")).ParseProgram()
+	stmt, ok := program.Statements[0].(*ast.DestructuringLetStatement)
+	if !ok {
+		t.Fatalf("stmt not *ast.DestructuringLetStatement. got=%T", program.Statements[0])
+	}
+	if len(stmt.Names) != 2 {
+		t.Fatalf("len(stmt.Names) not 2. got=%d", len(stmt.Names))
+	}
+	testIdentifier(t, stmt.Names[0], "a")
+	testIdentifier(t, stmt.Names[1], "b")
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, ...rest, b] = xs", "rest element ...rest must be the last name"},
+		{"let [] = xs", "expected next token to be IDENT, got ] instead"},
+		{"let [a, b = xs", "expected next token to be ], got = instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("%s gave wrong errors. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+
+	// A count mismatch is found once the whole statement has been read, so
+	// it is the only error and parsing carries on after it.
+	mismatchTests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let a, b = 1, 2, 3", []string{"cannot bind 3 values to 2 names in let a, b = (1, 2, 3);"}},
+		{"let a, b = 1, 2, 3; a", []string{"cannot bind 3 values to 2 names in let a, b = (1, 2, 3);"}},
+		{"let a, b, c = (1, 2);", []string{"cannot bind 2 values to 3 names in let a, b, c = (1, 2);"}},
+		{"let a, b = 1, 2, 3; let c, d = 4, 5, 6;", []string{
+			"cannot bind 3 values to 2 names in let a, b = (1, 2, 3);",
+			"cannot bind 3 values to 2 names in let c, d = (4, 5, 6);",
+		}},
+	}
+
+	for _, tt := range mismatchTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if !slices.Equal(p.Errors(), tt.expected) {
+			t.Errorf("%s gave wrong errors. expected=%q, got=%q", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "myArray[1 + 1]"
 	l := lexer.New(input)
@@ -747,3 +1793,275 @@ func TestParsingIndexExpressions(t *testing.T) {
 		return
 	}
 }
+
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:3]", "(a[1:3])"},
+		{"a[:2]", "(a[:2])"},
+		{"a[2:]", "(a[2:])"},
+		{"a[:]", "(a[:])"},
+		{"a[-2:-1]", "(a[(-2):(-1)])"},
+		{"a[i + 1:len(a)]", "(a[(i + 1):len(a)])"},
+		{"a[b ? 1 : 2]", "(a[(b ? 1 : 2)])"},
+		{"a[1:3][0]", "((a[1:3])[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New("a[1:2]")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	slice, ok := stmt.Expression.(*ast.SliceExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+	}
+	if !testIdentifier(t, slice.Left, "a") {
+		return
+	}
+	testIntegerLiteral(t, slice.Start, 1)
+	testIntegerLiteral(t, slice.End, 2)
+}
+
+func TestIndexAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1] = 99", "a[1] = 99"},
+		{"a[i + 1] = b * 2", "a[(i + 1)] = (b * 2)"},
+		{`h["key"] = "value"`, "h[key] = value"},
+		{"grid[0][1] = 5", "(grid[0])[1] = 5"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.IndexAssignmentExpression); !ok {
+			t.Fatalf("exp not *ast.IndexAssignmentExpression. got=%T", stmt.Expression)
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New("5 = 1")
+	p := New(l)
+	p.ParseProgram()
+	errors := p.Errors()
+	expected := "invalid assignment target. must be an identifier or index expression"
+	if len(errors) == 0 || errors[0] != expected {
+		t.Errorf("wrong parser errors. expected=%q, got=%v", expected, errors)
+	}
+}
+
+func TestCompoundAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x += 1", "x = (x + 1)"},
+		{"x -= 1", "x = (x - 1)"},
+		{"x *= 2 + 3", "x = (x * (2 + 3))"},
+		{"x /= y", "x = (x / y)"},
+		{"x += y += 1", "x = (x + y = (y + 1))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.AssignmentExpression); !ok {
+			t.Fatalf("exp not *ast.AssignmentExpression. got=%T", stmt.Expression)
+		}
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestCompoundAssignmentInvalidTarget(t *testing.T) {
+	l := lexer.New("a[0] += 5")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+	if errors[0] != "invalid assignment target. must be an identifier" {
+		t.Errorf("wrong error message. got=%q", errors[0])
+	}
+}
+
+func TestParsingHashLiteralsStringKeys(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	expected := []struct {
+		key   string
+		value int64
+	}{
+		{"one", 1},
+		{"two", 2},
+		{"three", 3},
+	}
+
+	for i, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
+			continue
+		}
+		if literal.Value != expected[i].key {
+			t.Errorf("key is not %q. got=%q", expected[i].key, literal.Value)
+		}
+		testIntegerLiteral(t, pair.Value, expected[i].value)
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	input := "{}"
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingHashLiteralsWithExpressions(t *testing.T) {
+	input := `{"one": 0 + 1, "two": 10 - 8, "three": 15 / 5}`
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	tests := map[string]func(ast.Expression){
+		"one": func(e ast.Expression) {
+			testInfixExpression(t, e, 0, "+", 1)
+		},
+		"two": func(e ast.Expression) {
+			testInfixExpression(t, e, 10, "-", 8)
+		},
+		"three": func(e ast.Expression) {
+			testInfixExpression(t, e, 15, "/", 5)
+		},
+	}
+
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
+			continue
+		}
+
+		testFunc, ok := tests[literal.Value]
+		if !ok {
+			t.Errorf("No test function for key %q found", literal.Value)
+			continue
+		}
+
+		testFunc(pair.Value)
+	}
+}
+
+func TestParsingHashLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"one" 1}`, "expected next token to be :, got INT instead"},
+		{`{"one": 1 "two": 2}`, "expected next token to be ,, got STRING instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestParseErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ParseError
+	}{
+		{"let x = 1;\nlet = 3;", ParseError{"expected next token to be IDENT, got = instead", 2, 5}},
+		{"1 +\n  )", ParseError{"no prefix parse function for ) found", 2, 3}},
+		{"a ?\n b", ParseError{"missing : in ternary expression a ? b, got EOF instead", 2, 3}},
+		{`let s = "${}";`, ParseError{`empty interpolation in string "${}"`, 1, 9}},
+		{"x = \"never closed", ParseError{"illegal token: unterminated string literal at position 5", 1, 5}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		errors := p.ErrorDetails()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong errors for %q. expected=%+v, got=%+v", tt.input, tt.expected, errors)
+		}
+	}
+}
@@ -2,47 +2,94 @@ package parser
 
 import (
 	"bananaScript/ast"
+	"bananaScript/lexer"
 	"bananaScript/token"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 const (
 	_ int = iota
 
 	LOWEST
-	ASSIGN      // =
+	ASSIGN      // =, +=, -=, *= or /=
+	TERNARY     // X ? Y : Z
+	COALESCE    // X ?? Y
 	EQUALS      // ==
-	LESSGREATER // > or <
+	LESSGREATER // >, <, >= or <=
+	BIT_OR      // |
+	BIT_XOR     // xor
+	BIT_AND     // &
+	SHIFT       // << or >>
 	SUM         // +
 	PRODUCT     // *
-	POWER       // ^
 	PREFIX      // -X or !X
+	POWER       // ^
+	POSTFIX     // X++ or X--
 	CALL        // myFunction(X)
 	INDEX       // array[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.ASSIGN:   ASSIGN,
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.CARET:    POWER,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.QUESTION:        TERNARY,
+	token.COALESCE:        COALESCE,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.IN:              LESSGREATER,
+	token.PIPE:            BIT_OR,
+	token.XOR:             BIT_XOR,
+	token.AMPERSAND:       BIT_AND,
+	token.LSHIFT:          SHIFT,
+	token.RSHIFT:          SHIFT,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.CARET:           POWER,
+	token.PLUSPLUS:        POSTFIX,
+	token.MINUSMINUS:      POSTFIX,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
 }
 
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
+	case token.FUNCTION:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.DO:
+		return p.parseDoWhileStatement()
+	case token.THROW:
+		return p.parseThrowStatement()
+	case token.IMPORT:
+		if !p.peekTokenIs(token.STRING) {
+			return p.parseExpressionStatement()
+		}
+		return p.parseImportStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 	case token.COMMENT:
 		// Skip comments and return nil to ignore them
 		return nil
@@ -87,9 +134,22 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
+	if index, ok := left.(*ast.IndexExpression); ok {
+		assignment := &ast.IndexAssignmentExpression{
+			Token: p.curToken,
+			Left:  index.Left,
+			Index: index.Index,
+		}
+
+		p.nextToken()
+		assignment.Value = p.parseExpression(LOWEST)
+
+		return assignment
+	}
+
 	ident, ok := left.(*ast.Identifier)
 	if !ok {
-		p.errors = append(p.errors, "invalid assignment target. must be an identifier")
+		p.addError("invalid assignment target. must be an identifier or index expression")
 		return nil
 	}
 
@@ -104,14 +164,50 @@ func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
 	return assignment
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: p.curToken}
+// parseCompoundAssignmentExpression desugars `x += y` into `x = x + y`.
+func (p *Parser) parseCompoundAssignmentExpression(left ast.Expression) ast.Expression {
+	ident, ok := left.(*ast.Identifier)
+	if !ok {
+		p.addError("invalid assignment target. must be an identifier")
+		return nil
+	}
+
+	assignment := &ast.AssignmentExpression{
+		Token: p.curToken,
+		Name:  ident,
+	}
+
+	operator := strings.TrimSuffix(p.curToken.Literal, "=")
+	operation := &ast.InfixExpression{
+		Token:    token.Token{Type: token.TokenType(operator), Literal: operator},
+		Left:     ident,
+		Operator: operator,
+	}
+
+	p.nextToken()
+	operation.Right = p.parseExpression(LOWEST)
+	assignment.Value = operation
+
+	return assignment
+}
+
+func (p *Parser) parseLetStatement() ast.Statement {
+	tok := p.curToken
+
+	if p.peekTokenIs(token.LBRACKET) {
+		p.nextToken()
+		return p.parseArrayDestructuringLetStatement(tok)
+	}
 
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	if p.peekTokenIs(token.COMMA) {
+		return p.parseDestructuringLetStatement(tok, name)
+	}
+	stmt := &ast.LetStatement{Token: tok, Name: name}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
@@ -128,12 +224,268 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return stmt
 }
 
+// parseDestructuringLetStatement parses the rest of `let a, b = value`
+// once the first name has been read. A comma-separated value is an
+// implicit tuple, so `let a, b = b, a` swaps, and it must have as many
+// values as there are names.
+func (p *Parser) parseDestructuringLetStatement(tok token.Token, first *ast.Identifier) ast.Statement {
+	stmt := &ast.DestructuringLetStatement{Token: tok, Names: []*ast.Identifier{first}}
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseImplicitTuple()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if tuple, ok := stmt.Value.(*ast.TupleLiteral); ok && len(tuple.Elements) != len(stmt.Names) {
+		p.errorAt(tok, fmt.Sprintf("cannot bind %d values to %d names in %s",
+			len(tuple.Elements), len(stmt.Names), stmt.String()))
+		return nil
+	}
+
+	return stmt
+}
+
+// parseArrayDestructuringLetStatement parses the rest of `let [a, b] =
+// value` from the opening bracket. The last name may be a `...rest` name.
+func (p *Parser) parseArrayDestructuringLetStatement(tok token.Token) ast.Statement {
+	stmt := &ast.DestructuringLetStatement{Token: tok, Array: true}
+
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			stmt.Rest = true
+		}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Names = append(stmt.Names, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		if stmt.Rest {
+			p.addError(fmt.Sprintf("rest element ...%s must be the last name", p.curToken.Literal))
+			return nil
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseImplicitTuple parses an expression, or a tuple of them when it is
+// followed by commas, as in `return a, b`.
+func (p *Parser) parseImplicitTuple() ast.Expression {
+	first := p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.COMMA) {
+		return first
+	}
+
+	tuple := &ast.TupleLiteral{
+		Token:    token.Token{Type: token.LPAREN, Literal: "("},
+		Elements: []ast.Expression{first},
+	}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
+	return tuple
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 	p.nextToken()
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	stmt.ReturnValue = p.parseImplicitTuple()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// parseForStatement parses `for (init; condition; post) { body }`. Each of
+// the three clauses may be left empty.
+func (p *Parser) parseForStatement() ast.Statement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseStatement()
+		if !p.curTokenIs(token.SEMICOLON) {
+			p.peekError(token.SEMICOLON)
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	if !p.curTokenIs(token.RPAREN) {
+		stmt.Post = p.parseStatement()
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement() ast.Statement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseDoWhileStatement() ast.Statement {
+	stmt := &ast.DoWhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if !p.expectPeek(token.WHILE) {
+		return nil
+	}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseImportExpression() ast.Expression {
+	exp := &ast.ImportExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	exp.Path = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return exp
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
 
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -147,21 +499,108 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	// defer untrace(trace("parseIntegerLiteral"))
 	lit := &ast.IntegerLiteral{Token: p.curToken}
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if !validDigitSeparators(p.curToken.Literal) {
+		msg := fmt.Sprintf("invalid digit separator in %q: underscores must sit between digits",
+			p.curToken.Literal)
+		p.addError(msg)
+		return nil
+	}
+	literal := strings.ReplaceAll(p.curToken.Literal, "_", "")
+	value, err := strconv.ParseInt(literal, 0, 64)
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+	whole, fraction, _ := strings.Cut(p.curToken.Literal, ".")
+	if !validDigitSeparators(whole) || !validDigitSeparators(fraction) {
+		msg := fmt.Sprintf("invalid digit separator in %q: underscores must sit between digits",
+			p.curToken.Literal)
+		p.addError(msg)
+		return nil
+	}
+	literal := strings.ReplaceAll(p.curToken.Literal, "_", "")
+	value, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.addError(msg)
+		return nil
+	}
+	lit.Value = value
+	return lit
+}
+
+// validDigitSeparators reports whether every underscore in an integer
+// literal sits between two digits, not after a 0x, 0o or 0b prefix.
+func validDigitSeparators(literal string) bool {
+	if len(literal) > 2 && literal[0] == '0' && strings.ContainsRune("xXoObB", rune(literal[1])) {
+		literal = literal[2:]
+	}
+	return !strings.HasPrefix(literal, "_") && !strings.HasSuffix(literal, "_") &&
+		!strings.Contains(literal, "__")
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	// defer untrace(trace("parseStringLiteral"))
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// parseInterpolatedString parses each `${...}` of a TEMPLATE token with a
+// parser of its own, since the lexer hands the string over as raw source.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+
+	parts, err := lexer.SplitTemplate(p.curToken.Literal)
+	if err != nil {
+		p.addError(err.Error())
+		return nil
+	}
+
+	for _, part := range parts {
+		if !part.IsExpression {
+			str.Parts = append(str.Parts, &ast.StringLiteral{
+				Token: token.Token{Type: token.STRING, Literal: part.Text},
+				Value: part.Text,
+			})
+			continue
+		}
+
+		expr := p.parseInterpolation(part.Text)
+		if expr == nil {
+			return nil
+		}
+		str.Parts = append(str.Parts, expr)
+	}
+
+	return str
+}
+
+func (p *Parser) parseInterpolation(source string) ast.Expression {
+	inner := New(lexer.New(source))
+	if inner.curTokenIs(token.EOF) {
+		p.addError(fmt.Sprintf("empty interpolation in string %q", p.curToken.Literal))
+		return nil
+	}
+
+	expr := inner.parseExpression(LOWEST)
+	if len(inner.errors) == 0 && !inner.peekTokenIs(token.EOF) {
+		inner.addError(fmt.Sprintf("unexpected %s after expression", inner.peekToken.Type))
+	}
+	for _, msg := range inner.Errors() {
+		p.addError(fmt.Sprintf("in interpolation of string %q: %s", p.curToken.Literal, msg))
+	}
+	if len(inner.errors) > 0 {
+		return nil
+	}
+	return expr
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	// defer untrace(trace("parsePrefixExpression"))
 	expression := &ast.PrefixExpression{
@@ -173,6 +612,14 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parsePostfixExpression(left ast.Expression) ast.Expression {
+	return &ast.PostfixExpression{
+		Token:    p.curToken,
+		Left:     left,
+		Operator: p.curToken.Literal,
+	}
+}
+
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	// defer untrace(trace("parseInfixExpression"))
 	expression := &ast.InfixExpression{
@@ -181,27 +628,90 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 	}
 	precedence := p.curPrecedence()
+	if p.curTokenIs(token.CARET) {
+		// ^ is right-associative: 2 ^ 3 ^ 2 parses as 2 ^ (3 ^ 2).
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 	return expression
 }
 
+func (p *Parser) parseTernaryExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.TernaryExpression{Token: p.curToken, Condition: condition}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.peekTokenIs(token.COLON) {
+		msg := fmt.Sprintf("missing : in ternary expression %s ? %s, got %s instead",
+			condition, expression.Consequence, p.peekToken.Type)
+		p.errorAt(p.peekToken, msg)
+		return nil
+	}
+	p.nextToken()
+
+	// Parsing the alternative just below TERNARY makes the operator
+	// right-associative: a ? b : c ? d : e is a ? b : (c ? d : e).
+	p.nextToken()
+	expression.Alternative = p.parseExpression(TERNARY - 1)
+	return expression
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
+
+// parseGroupedExpression parses a parenthesised expression, or a tuple
+// literal when the parentheses are empty or hold a comma: `()`, `(1,)`,
+// `(1, 2)`.
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	tuple := &ast.TupleLiteral{Token: p.curToken, Elements: []ast.Expression{}}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return tuple
+	}
+
 	p.nextToken()
 	exp := p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.COMMA) {
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return exp
+	}
+
+	tuple.Elements = append(tuple.Elements, exp)
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
-	return exp
+	return tuple
+}
+
+// parseIllegalToken reports an ILLEGAL token from the lexer, whose literal
+// is either the offending character or a description of a malformed string.
+func (p *Parser) parseIllegalToken() ast.Expression {
+	msg := fmt.Sprintf("illegal token: %s", p.curToken.Literal)
+	p.addError(msg)
+	return nil
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(msg)
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -236,6 +746,21 @@ func (p *Parser) parseIfExpression() ast.Expression {
 
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
+
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			elseIf := &ast.ExpressionStatement{Token: p.curToken}
+			elseIf.Expression = p.parseIfExpression()
+			if elseIf.Expression == nil {
+				return nil
+			}
+			expression.Alternative = &ast.BlockStatement{
+				Token:      elseIf.Token,
+				Statements: []ast.Statement{elseIf},
+			}
+			return expression
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -244,6 +769,46 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH) {
+		p.nextToken()
+		if !p.expectPeek(token.LPAREN) {
+			return nil
+		}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		expression.Param = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY) {
+		p.nextToken()
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		p.addError("try without catch or finally")
+		return nil
+	}
+	return expression
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
@@ -258,6 +823,73 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	return block
 }
 
+// parseMatchExpression parses `match (x) { 1 => "one", _ => "many" }`.
+// Arms are separated by commas, and a trailing comma is allowed. An arm
+// repeating an earlier literal pattern, or following the `_` arm, can
+// never be chosen and is reported as a warning.
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	hasDefault := false
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := &ast.MatchArm{}
+		if !p.curTokenIs(token.IDENT) || p.curToken.Literal != "_" {
+			arm.Pattern = p.parseExpression(LOWEST)
+		}
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		p.nextToken()
+		arm.Value = p.parseExpression(LOWEST)
+
+		if hasDefault {
+			p.warnings = append(p.warnings, fmt.Sprintf("unreachable match arm %s after _", arm))
+		} else if arm.Pattern == nil {
+			hasDefault = true
+		} else if isLiteral(arm.Pattern) {
+			key := fmt.Sprintf("%T %s", arm.Pattern, arm.Pattern)
+			if seen[key] {
+				p.warnings = append(p.warnings, fmt.Sprintf("duplicate match arm %s", arm))
+			}
+			seen[key] = true
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return expression
+}
+
+func isLiteral(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral,
+		*ast.Boolean, *ast.NullLiteral:
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 
@@ -265,7 +897,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -275,30 +909,96 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parseFunctionStatement parses a named declaration `fn name(...) { ... }`,
+// which is shorthand for `let name = fn(...) { ... }`.
+func (p *Parser) parseFunctionStatement() ast.Statement {
+	fnToken := p.curToken
+	p.nextToken()
+	stmt := &ast.LetStatement{
+		Token: token.Token{Type: token.LET, Literal: "let"},
+		Name:  &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+	}
 
-	if p.peekTokenIs(token.RPAREN) {
+	lit := &ast.FunctionLiteral{Token: fnToken}
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	lit.Body = p.parseBlockStatement()
+	stmt.Value = lit
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
-		return identifiers
 	}
 
-	p.nextToken()
+	return stmt
+}
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+// parseFunctionParameters fills in lit's parameter list, which may give
+// parameters `= default` values and end in a `...rest` parameter. Once a
+// parameter has a default, every later one but the rest parameter must
+// have one too.
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
 
-	for p.peekTokenIs(token.COMMA) {
+	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
+		return true
+	}
+
+	for {
 		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			lit.Parameters = append(lit.Parameters, ident)
+			lit.Defaults = append(lit.Defaults, nil)
+			lit.Variadic = true
+			if p.peekTokenIs(token.COMMA) {
+				p.nextToken()
+				if !p.peekTokenIs(token.RPAREN) {
+					p.addError(fmt.Sprintf("rest parameter ...%s must be the last parameter", ident.Value))
+					return false
+				}
+			}
+			if p.peekTokenIs(token.ASSIGN) {
+				p.addError(fmt.Sprintf("rest parameter ...%s cannot have a default value", ident.Value))
+				return false
+			}
+			return p.expectPeek(token.RPAREN)
+		}
+
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
-	}
+		var defaultValue ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			defaultValue = p.parseExpression(LOWEST)
+		} else if len(lit.Defaults) > 0 && lit.Defaults[len(lit.Defaults)-1] != nil {
+			p.addError(fmt.Sprintf("parameter %s without a default cannot follow one with a default", ident.Value))
+			return false
+		}
+		lit.Parameters = append(lit.Parameters, ident)
+		lit.Defaults = append(lit.Defaults, defaultValue)
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
 	}
-	return identifiers
+
+	return p.expectPeek(token.RPAREN)
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -307,6 +1007,19 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	return exp
 }
 
+// parseListElement parses a call argument or array element, either of
+// which may be a `...arr` spread.
+func (p *Parser) parseListElement() ast.Expression {
+	if !p.curTokenIs(token.ELLIPSIS) {
+		return p.parseExpression(LOWEST)
+	}
+
+	spread := &ast.SpreadExpression{Token: p.curToken}
+	p.nextToken()
+	spread.Value = p.parseExpression(LOWEST)
+	return spread
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 	array.Elements = p.parseExpressionList(token.RBRACKET)
@@ -320,11 +1033,14 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 		return list
 	}
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+	list = append(list, p.parseListElement())
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		if p.peekTokenIs(end) {
+			break
+		}
 		p.nextToken()
-		list = append(list, p.parseExpression(LOWEST))
+		list = append(list, p.parseListElement())
 	}
 	if !p.expectPeek(end) {
 		return nil
@@ -333,12 +1049,65 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 }
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
 	p.nextToken()
 
-	exp.Index = p.parseExpression(LOWEST)
+	if p.curTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, nil)
+	}
+
+	index := p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	exp := &ast.IndexExpression{Token: tok, Left: left, Index: index}
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
 	return exp
 }
+
+// parseSliceExpression parses the remainder of `left[start:end]` with the
+// current token on the colon.
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return exp
+}
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken}
+	hash.Pairs = []ast.HashLiteralPair{}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	return hash
+}
@@ -8,22 +8,28 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"strings"
 )
 
 const PROMPT = ">> "
 
+// Start runs the REPL. Lines typed in and lines read by input() come from
+// the same reader, so that neither buffers input meant for the other.
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	reader := bufio.NewReader(in)
 	env := object.NewEnvironment()
+	evaluator.SetOutput(env, out)
+	evaluator.SetInput(env, reader)
 
 	for {
 		fmt.Print(PROMPT)
-		scanned := scanner.Scan()
-		if !scanned {
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
 			return
 		}
 
-		line := scanner.Text()
+		line = strings.TrimRight(line, "\r\n")
 		l := lexer.New(line)
 		p := parser.New(l)
 
@@ -32,8 +38,11 @@ func Start(in io.Reader, out io.Writer) {
 			printParserErrors(out, p.Errors())
 			continue
 		}
+		for _, msg := range p.Warnings() {
+			io.WriteString(out, "warning: "+msg+"\n")
+		}
 
-		evaluated := evaluator.Eval(program, env)
+		evaluated := evaluator.EvalWithResolver(program, env, evaluator.FileResolver{}, "")
 		if evaluated != nil {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
@@ -41,6 +50,37 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
+// RunFile evaluates the program in the file at path, resolving its imports
+// relative to it and reading input() from in, and reports whether it ran
+// without errors.
+func RunFile(path string, in io.Reader, out io.Writer) bool {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(out, "could not read %s: %s\n", path, err)
+		return false
+	}
+
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		printParserErrors(out, p.Errors())
+		return false
+	}
+	for _, msg := range p.Warnings() {
+		io.WriteString(out, "warning: "+msg+"\n")
+	}
+
+	env := object.NewEnvironment()
+	evaluator.SetOutput(env, out)
+	evaluator.SetInput(env, in)
+	evaluated := evaluator.EvalWithResolver(program, env, evaluator.FileResolver{}, path)
+	if errObj, ok := evaluated.(*object.Error); ok {
+		io.WriteString(out, errObj.Inspect()+"\n")
+		return false
+	}
+	return true
+}
+
 func printParserErrors(out io.Writer, errors []string) {
 	io.WriteString(out, "Woops! We ran into some bananaScript business here!\n")
 	io.WriteString(out, " parser errors:\n")
@@ -2,9 +2,14 @@ package token
 
 type TokenType string
 
+// Token is a lexeme along with where it starts in the source: Line and
+// Column count from 1, Column in runes. Tokens the parser makes up have
+// neither.
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int
+	Column  int
 }
 
 const (
@@ -12,31 +17,51 @@ const (
 	EOF     = "EOF"
 
 	// Identifiers + literals
-	IDENT  = "IDENT" // add, foobar, x, y, ...
-	INT    = "INT"   // 1343456
-	STRING = "STRING"
+	IDENT    = "IDENT" // add, foobar, x, y, ...
+	INT      = "INT"   // 1343456
+	FLOAT    = "FLOAT" // 3.14
+	STRING   = "STRING"
+	TEMPLATE = "TEMPLATE" // "sum is ${a + b}"
 
 	// Operators
-	ASSIGN     = "="
-	ASSIGNMENT = "ASSIGN"
-	COMPARISON = "=="
-	PLUS       = "+"
-	MINUS      = "-"
-	BANG       = "!"
-	ASTERISK   = "*"
-	SLASH      = "/"
-	CARET      = "^"
-	EQ         = "=="
-	NOT_EQ     = "!="
+	ASSIGN          = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	ASSIGNMENT      = "ASSIGN"
+	COMPARISON      = "=="
+	PLUS            = "+"
+	MINUS           = "-"
+	PLUSPLUS        = "++"
+	MINUSMINUS      = "--"
+	BANG            = "!"
+	ASTERISK        = "*"
+	SLASH           = "/"
+	CARET           = "^"
+	EQ              = "=="
+	NOT_EQ          = "!="
+	AMPERSAND       = "&"
+	PIPE            = "|"
+	TILDE           = "~"
+	LSHIFT          = "<<"
+	RSHIFT          = ">>"
 
 	COMMENT = "//"
 
-	LT = "<"
-	GT = ">"
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
 
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	QUESTION  = "?"
+	COALESCE  = "??"
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
+	COLON     = ":"
 
 	LPAREN = "("
 	RPAREN = ")"
@@ -49,21 +74,51 @@ const (
 	// Keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	CONST    = "CONST"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	NULL     = "NULL"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	FOR      = "FOR"
+	WHILE    = "WHILE"
+	DO       = "DO"
+	XOR      = "XOR"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	MATCH    = "MATCH"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
+	FINALLY  = "FINALLY"
+	THROW    = "THROW"
+	IMPORT   = "IMPORT"
+	IN       = "IN"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"const":    CONST,
+	"true":     TRUE,
+	"false":    FALSE,
+	"null":     NULL,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"for":      FOR,
+	"while":    WHILE,
+	"do":       DO,
+	"xor":      XOR,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"match":    MATCH,
+	"try":      TRY,
+	"catch":    CATCH,
+	"finally":  FINALLY,
+	"throw":    THROW,
+	"import":   IMPORT,
+	"in":       IN,
 }
 
 func LookUpIdent(ident string) TokenType {